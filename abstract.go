@@ -0,0 +1,214 @@
+package crunchio
+
+import (
+	"encoding"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// ReadAbstract decodes a value written by WriteAbstract from the current
+// offset into the value pointed to by dst, time.Time values are returned in UTC
+// and slices are filled to their existing length (see ReadAbstractN)
+func (b *Buffer) ReadAbstract(dst any) (err error) {
+	if b == nil {
+		panic("READABSTRACT: buffer is nil")
+	}
+	switch dst := dst.(type) {
+	case *int8:
+		*dst, err = ReadValue[int8](b)
+	case *uint8:
+		*dst, err = ReadValue[uint8](b)
+	case *int16:
+		*dst, err = ReadValue[int16](b)
+	case *uint16:
+		*dst, err = ReadValue[uint16](b)
+	case *int32:
+		*dst, err = ReadValue[int32](b)
+	case *uint32:
+		*dst, err = ReadValue[uint32](b)
+	case *int64:
+		*dst, err = ReadValue[int64](b)
+	case *uint64:
+		*dst, err = ReadValue[uint64](b)
+	case *int:
+		var number int64
+		if number, err = ReadValue[int64](b); err == nil {
+			if int64(int(number)) != number {
+				return fmt.Errorf("buffer: readabstract: %d overflows int", number)
+			}
+			*dst = int(number)
+		}
+	case *uint:
+		var number uint64
+		if number, err = ReadValue[uint64](b); err == nil {
+			if uint64(uint(number)) != number {
+				return fmt.Errorf("buffer: readabstract: %d overflows uint", number)
+			}
+			*dst = uint(number)
+		}
+	case *bool:
+		var flag uint8
+		if flag, err = ReadValue[uint8](b); err == nil {
+			*dst = flag != 0
+		}
+	case *float32:
+		*dst, err = ReadValue[float32](b)
+	case *float64:
+		*dst, err = ReadValue[float64](b)
+	case *time.Time:
+		var nanoseconds int64
+		if nanoseconds, err = ReadValue[int64](b); err == nil {
+			*dst = time.Unix(0, nanoseconds).UTC()
+		}
+	case *time.Duration:
+		var nanoseconds int64
+		if nanoseconds, err = ReadValue[int64](b); err == nil {
+			*dst = time.Duration(nanoseconds)
+		}
+	case *[]byte:
+		err = b.readExact(*dst)
+	case *[]int16:
+		err = readValues(b, *dst)
+	case *[]uint16:
+		err = readValues(b, *dst)
+	case *[]int32:
+		err = readValues(b, *dst)
+	case *[]uint32:
+		err = readValues(b, *dst)
+	case *[]int64:
+		err = readValues(b, *dst)
+	case *[]uint64:
+		err = readValues(b, *dst)
+	case *[]float32:
+		err = readValues(b, *dst)
+	case *[]float64:
+		err = readValues(b, *dst)
+	case *complex64:
+		var scratch [8]byte
+		if err = b.readExact(scratch[:]); err == nil {
+			*dst = complex64At(scratch[:], b.byteOrder())
+		}
+	case *[]complex64:
+		scratch := make([]byte, 8*len(*dst))
+		if err = b.readExact(scratch); err == nil {
+			for i := range *dst {
+				(*dst)[i] = complex64At(scratch[8*i:], b.byteOrder())
+			}
+		}
+	case *complex128:
+		var scratch [16]byte
+		if err = b.readExact(scratch[:]); err == nil {
+			*dst = complex128At(scratch[:], b.byteOrder())
+		}
+	case *[]complex128:
+		scratch := make([]byte, 16*len(*dst))
+		if err = b.readExact(scratch); err == nil {
+			for i := range *dst {
+				(*dst)[i] = complex128At(scratch[16*i:], b.byteOrder())
+			}
+		}
+	default:
+		err = fmt.Errorf("buffer: Unsupported type for abstract read: %T", dst)
+	}
+	return
+}
+
+// ReadAbstractN reads n elements into a slice pointed to by dst, replacing
+// it, n bytes into a *string, or the next n bytes into a json.Unmarshaler or,
+// failing that, an encoding.TextUnmarshaler, the counterpart of WriteAbstract
+// for values whose encoded length is known, restoring the offset on failure
+func (b *Buffer) ReadAbstractN(dst any, n int) error {
+	if b == nil {
+		panic("READABSTRACTN: buffer is nil")
+	}
+	if n < 0 {
+		return fmt.Errorf("buffer: readabstractn: negative length %d", n)
+	}
+	var unmarshal func([]byte) error
+	switch dst := dst.(type) {
+	case *string:
+		s, err := b.ReadString(n)
+		if err == nil {
+			*dst = s
+		}
+		return err
+	case *[]byte:
+		return readAbstractSlice(b, dst, n)
+	case *[]int16:
+		return readAbstractSlice(b, dst, n)
+	case *[]uint16:
+		return readAbstractSlice(b, dst, n)
+	case *[]int32:
+		return readAbstractSlice(b, dst, n)
+	case *[]uint32:
+		return readAbstractSlice(b, dst, n)
+	case *[]int64:
+		return readAbstractSlice(b, dst, n)
+	case *[]uint64:
+		return readAbstractSlice(b, dst, n)
+	case *[]float32:
+		return readAbstractSlice(b, dst, n)
+	case *[]float64:
+		return readAbstractSlice(b, dst, n)
+	case *[]complex64:
+		return readAbstractSlice(b, dst, n)
+	case *[]complex128:
+		return readAbstractSlice(b, dst, n)
+	case json.Unmarshaler:
+		unmarshal = dst.UnmarshalJSON
+	case encoding.TextUnmarshaler:
+		unmarshal = dst.UnmarshalText
+	default:
+		return fmt.Errorf("buffer: Unsupported type for abstract read: %T", dst)
+	}
+	start := b.Tell()
+	data := make([]byte, n)
+	if err := b.readExact(data); err != nil {
+		if err == io.EOF && n > 0 {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	if err := unmarshal(data); err != nil {
+		b.Seek(start, io.SeekStart)
+		return fmt.Errorf("buffer: readabstractn: %w", err)
+	}
+	return nil
+}
+
+// readAbstractSlice reads n elements into a new slice through ReadAbstract,
+// only replacing *dst once they have all been read
+func readAbstractSlice[T any](b *Buffer, dst *[]T, n int) error {
+	elements := make([]T, n)
+	if err := b.ReadAbstract(&elements); err != nil {
+		return err
+	}
+	*dst = elements
+	return nil
+}
+
+// putComplex64 encodes c into data as two float32 values, real then imaginary
+func putComplex64(data []byte, order binary.ByteOrder, c complex64) {
+	order.PutUint32(data, math.Float32bits(real(c)))
+	order.PutUint32(data[4:], math.Float32bits(imag(c)))
+}
+
+// putComplex128 encodes c into data as two float64 values, real then imaginary
+func putComplex128(data []byte, order binary.ByteOrder, c complex128) {
+	order.PutUint64(data, math.Float64bits(real(c)))
+	order.PutUint64(data[8:], math.Float64bits(imag(c)))
+}
+
+// complex64At decodes a complex64 written by putComplex64 from data
+func complex64At(data []byte, order binary.ByteOrder) complex64 {
+	return complex(math.Float32frombits(order.Uint32(data)), math.Float32frombits(order.Uint32(data[4:])))
+}
+
+// complex128At decodes a complex128 written by putComplex128 from data
+func complex128At(data []byte, order binary.ByteOrder) complex128 {
+	return complex(math.Float64frombits(order.Uint64(data)), math.Float64frombits(order.Uint64(data[8:])))
+}
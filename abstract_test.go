@@ -0,0 +1,249 @@
+package crunchio
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+	"net/netip"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestAbstractTime(t *testing.T) {
+	when := time.Date(2024, time.February, 29, 13, 14, 15, 123456789, time.FixedZone("UTC+2", 2*60*60))
+	elapsed := 90*time.Minute + 42*time.Nanosecond
+	b := NewBuffer("time")
+	b.SetByteOrder(binary.BigEndian)
+	b.WriteAbstract(when)
+	b.WriteAbstract(elapsed)
+	b.WriteAbstract(-elapsed)
+	want := binary.BigEndian.AppendUint64(nil, uint64(when.UnixNano()))
+	if got := b.Bytes()[:8]; !slices.Equal(got, want) {
+		t.Fatalf("time.Time encoded as % x, want Unix nanoseconds % x", got, want)
+	}
+
+	b.Seek(0, io.SeekStart)
+	var gotWhen time.Time
+	var gotElapsed, gotNegative time.Duration
+	for _, dst := range []any{&gotWhen, &gotElapsed, &gotNegative} {
+		if err := b.ReadAbstract(dst); err != nil {
+			t.Fatalf("ReadAbstract(%T) = %v", dst, err)
+		}
+	}
+	if !gotWhen.Equal(when) || gotWhen.Location() != time.UTC {
+		t.Fatalf("time.Time read back as %v, want %v in UTC", gotWhen, when)
+	}
+	if gotElapsed != elapsed || gotNegative != -elapsed {
+		t.Fatalf("time.Duration read back as %v and %v, want %v", gotElapsed, gotNegative, elapsed)
+	}
+	if err := b.ReadAbstract(&gotWhen); err != io.EOF {
+		t.Fatalf("ReadAbstract at the end = %v, want io.EOF", err)
+	}
+}
+
+func TestAbstractTimeRange(t *testing.T) {
+	b := NewBuffer("time")
+	for _, when := range []time.Time{
+		{},
+		time.Date(1677, time.September, 21, 0, 12, 43, 145224191, time.UTC),
+		time.Date(2262, time.April, 11, 23, 47, 16, 854775808, time.UTC),
+		time.Date(3000, time.January, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		if n, err := b.WriteAbstract(when); n != 0 || err == nil {
+			t.Fatalf("WriteAbstract(%v) = %d, %v, want an out of range error", when, n, err)
+		}
+	}
+	if b.Size() != 0 {
+		t.Fatalf("out of range times wrote %d bytes", b.Size())
+	}
+
+	edges := []time.Time{
+		time.Date(1677, time.September, 21, 0, 12, 43, 145224192, time.UTC),
+		time.Date(2262, time.April, 11, 23, 47, 16, 854775807, time.UTC),
+	}
+	for _, when := range edges {
+		if _, err := b.WriteAbstract(when); err != nil {
+			t.Fatalf("WriteAbstract(%v) = %v", when, err)
+		}
+	}
+	b.Seek(0, io.SeekStart)
+	for _, want := range edges {
+		var got time.Time
+		if err := b.ReadAbstract(&got); err != nil || !got.Equal(want) {
+			t.Fatalf("ReadAbstract(*time.Time) = %v, %v, want %v", got, err, want)
+		}
+	}
+}
+
+func TestAbstractComplex(t *testing.T) {
+	c64 := complex64(complex(1.5, -2.25))
+	c128 := complex(math.Pi, -math.E)
+	s64 := []complex64{1 + 2i, -3 - 4i}
+	s128 := []complex128{complex(math.Inf(1), 0), 0, -1i}
+	b := NewBuffer("complex")
+	b.SetByteOrder(binary.LittleEndian)
+	for _, v := range []any{c64, c128, s64, s128} {
+		if _, err := b.WriteAbstract(v); err != nil {
+			t.Fatalf("WriteAbstract(%T) = %v", v, err)
+		}
+	}
+	if b.Size() != int64(8+16+8*len(s64)+16*len(s128)) {
+		t.Fatalf("complex values took %d bytes", b.Size())
+	}
+	want := binary.LittleEndian.AppendUint32(nil, math.Float32bits(1.5))
+	want = binary.LittleEndian.AppendUint32(want, math.Float32bits(-2.25))
+	if got := b.Bytes()[:8]; !slices.Equal(got, want) {
+		t.Fatalf("complex64 encoded as % x, want real then imaginary % x", got, want)
+	}
+
+	b.Seek(0, io.SeekStart)
+	var gotC64 complex64
+	var gotC128 complex128
+	gotS64 := make([]complex64, len(s64))
+	var gotS128 []complex128
+	for _, dst := range []any{&gotC64, &gotC128, &gotS64} {
+		if err := b.ReadAbstract(dst); err != nil {
+			t.Fatalf("ReadAbstract(%T) = %v", dst, err)
+		}
+	}
+	if err := b.ReadAbstractN(&gotS128, len(s128)); err != nil {
+		t.Fatalf("ReadAbstractN = %v", err)
+	}
+	if gotC64 != c64 || gotC128 != c128 || !slices.Equal(gotS64, s64) || !slices.Equal(gotS128, s128) {
+		t.Fatalf("read back %v, %v, %v, %v", gotC64, gotC128, gotS64, gotS128)
+	}
+	b.Seek(4, io.SeekStart)
+	b.Truncate(10)
+	if err := b.ReadAbstract(&gotC64); err == nil {
+		t.Fatal("ReadAbstract of a truncated complex64 succeeded")
+	}
+}
+
+func TestAbstractInt8(t *testing.T) {
+	b := NewBuffer("int8")
+	values := []int8{0, -3, math.MinInt8, math.MaxInt8}
+	for _, v := range values {
+		if n, err := b.WriteAbstract(v); n != 1 || err != nil {
+			t.Fatalf("WriteAbstract(int8 %d) = %d, %v, want 1 byte", v, n, err)
+		}
+	}
+	if got := b.Bytes(); !slices.Equal(got, []byte{0x00, 0xFD, 0x80, 0x7F}) {
+		t.Fatalf("int8 values encoded as % x", got)
+	}
+	b.Seek(0, io.SeekStart)
+	for _, want := range values {
+		var got int8
+		if err := b.ReadAbstract(&got); err != nil || got != want {
+			t.Fatalf("ReadAbstract(*int8) = %d, %v, want %d", got, err, want)
+		}
+	}
+}
+
+func TestAbstractPlatformInts(t *testing.T) {
+	b := NewBuffer("ints")
+	b.SetByteOrder(binary.LittleEndian)
+	ints := []int{0, -1, math.MinInt32, math.MaxInt32, math.MinInt, math.MaxInt}
+	uints := []uint{0, math.MaxUint32, math.MaxUint}
+	for _, v := range ints {
+		if n, err := b.WriteAbstract(v); n != 8 || err != nil {
+			t.Fatalf("WriteAbstract(int %d) = %d, %v, want 8 bytes", v, n, err)
+		}
+	}
+	for _, v := range uints {
+		if n, err := b.WriteAbstract(v); n != 8 || err != nil {
+			t.Fatalf("WriteAbstract(uint %d) = %d, %v, want 8 bytes", v, n, err)
+		}
+	}
+	if got := b.Bytes()[8:16]; !slices.Equal(got, []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}) {
+		t.Fatalf("int -1 encoded as % x, want it sign-extended to 64 bits", got)
+	}
+
+	b.Seek(0, io.SeekStart)
+	for _, want := range ints {
+		var got int
+		if err := b.ReadAbstract(&got); err != nil || got != want {
+			t.Fatalf("ReadAbstract(*int) = %d, %v, want %d", got, err, want)
+		}
+	}
+	for _, want := range uints {
+		var got uint
+		if err := b.ReadAbstract(&got); err != nil || got != want {
+			t.Fatalf("ReadAbstract(*uint) = %d, %v, want %d", got, err, want)
+		}
+	}
+
+	// a 64-bit value only fits an int on 64-bit platforms
+	wide := NewBuffer("ints")
+	wide.WriteAbstract(int64(1) << 40)
+	wide.WriteAbstract(uint64(1) << 40)
+	wide.Seek(0, io.SeekStart)
+	var i int
+	var u uint
+	errInt, errUint := wide.ReadAbstract(&i), wide.ReadAbstract(&u)
+	if strconv.IntSize == 64 {
+		if errInt != nil || errUint != nil || int64(i) != 1<<40 || uint64(u) != 1<<40 {
+			t.Fatalf("ReadAbstract of 1<<40 = %d, %v and %d, %v", i, errInt, u, errUint)
+		}
+	} else if errInt == nil || errUint == nil {
+		t.Fatalf("ReadAbstract of 1<<40 on a %d-bit platform = %d, %d", strconv.IntSize, i, u)
+	}
+
+	strict := NewBuffer("ints")
+	strict.DisallowPlatformInts(true)
+	for _, v := range []any{int(1), uint(1)} {
+		if _, err := strict.WriteAbstract(v); err == nil {
+			t.Fatalf("WriteAbstract(%T) with platform ints disallowed succeeded", v)
+		}
+	}
+	if _, err := strict.WriteAbstract(int32(1)); err != nil || strict.Size() != 4 {
+		t.Fatalf("WriteAbstract(int32) with platform ints disallowed = %v", err)
+	}
+}
+
+// point marshals itself to JSON and back
+type point struct {
+	X, Y int
+}
+
+func (p point) MarshalJSON() ([]byte, error) {
+	return json.Marshal([2]int{p.X, p.Y})
+}
+
+func (p *point) UnmarshalJSON(data []byte) error {
+	var pair [2]int
+	if err := json.Unmarshal(data, &pair); err != nil {
+		return err
+	}
+	p.X, p.Y = pair[0], pair[1]
+	return nil
+}
+
+func TestAbstractMarshalers(t *testing.T) {
+	b := NewBuffer("marshal")
+	n, err := b.WriteAbstract(point{3, -4})
+	if err != nil || b.String() != "[3,-4]" {
+		t.Fatalf("WriteAbstract(json.Marshaler) = %d, %v, wrote %q", n, err, b.String())
+	}
+	ip := netip.MustParseAddr("192.0.2.1")
+	m, err := b.WriteAbstract(ip)
+	if err != nil || b.String() != "[3,-4]192.0.2.1" {
+		t.Fatalf("WriteAbstract(encoding.TextMarshaler) = %d, %v, buffer holds %q", m, err, b.String())
+	}
+
+	b.Seek(0, io.SeekStart)
+	var p point
+	if err := b.ReadAbstractN(&p, n); err != nil || p != (point{3, -4}) {
+		t.Fatalf("ReadAbstractN(json.Unmarshaler) = %+v, %v", p, err)
+	}
+	var gotIP netip.Addr
+	if err := b.ReadAbstractN(&gotIP, m); err != nil || gotIP != ip {
+		t.Fatalf("ReadAbstractN(encoding.TextUnmarshaler) = %v, %v", gotIP, err)
+	}
+	b.Seek(1, io.SeekStart)
+	if err := b.ReadAbstractN(&p, n); err == nil || b.Tell() != 1 {
+		t.Fatalf("ReadAbstractN of bad JSON = %v, left the offset at %d", err, b.Tell())
+	}
+}
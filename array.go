@@ -0,0 +1,60 @@
+package crunchio
+
+import (
+	"fmt"
+	"io"
+)
+
+// SetTruncateArrays controls whether WriteArray truncates data longer than the
+// array instead of returning an error
+func (b *Buffer) SetTruncateArrays(truncate bool) {
+	if b == nil {
+		panic("SETTRUNCATEARRAYS: buffer is nil")
+	}
+	b.Lock()
+	defer b.Unlock()
+	b.truncateArrays = truncate
+}
+
+// ReadArray reads exactly n bytes at the current offset, returning
+// io.ErrUnexpectedEOF without advancing if fewer remain
+func (b *Buffer) ReadArray(n int) ([]byte, error) {
+	if b == nil {
+		panic("READARRAY: buffer is nil")
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("buffer: readarray: negative length %d", n)
+	}
+	data := make([]byte, n)
+	if err := b.readExact(data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+// WriteArray writes exactly n bytes at the current offset, zero-padding data
+// that is shorter than n
+func (b *Buffer) WriteArray(data []byte, n int) error {
+	if b == nil {
+		panic("WRITEARRAY: buffer is nil")
+	}
+	if n < 0 {
+		return fmt.Errorf("buffer: writearray: negative length %d", n)
+	}
+	if len(data) > n {
+		b.RLock()
+		truncate := b.truncateArrays
+		b.RUnlock()
+		if !truncate {
+			return fmt.Errorf("buffer: writearray: %d bytes do not fit in an array of %d", len(data), n)
+		}
+		data = data[:n]
+	}
+	array := make([]byte, n)
+	copy(array, data)
+	_, err := b.Write(array)
+	return err
+}
@@ -0,0 +1,50 @@
+package crunchio
+
+import (
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestWriteArray(t *testing.T) {
+	b := NewBuffer("array")
+	if err := b.WriteArray([]byte("ab"), 4); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.WriteArray([]byte("cdef"), 4); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.Bytes(); !slices.Equal(got, []byte("ab\x00\x00cdef")) {
+		t.Fatalf("WriteArray wrote %q", got)
+	}
+	if err := b.WriteArray([]byte("toolong"), 4); err == nil {
+		t.Fatal("WriteArray of an overflowing value succeeded")
+	}
+	if b.Size() != 8 {
+		t.Fatalf("failed WriteArray grew the buffer to %d", b.Size())
+	}
+	b.SetTruncateArrays(true)
+	if err := b.WriteArray([]byte("toolong"), 4); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != "ab\x00\x00cdeftool" {
+		t.Fatalf("truncating WriteArray wrote %q", got)
+	}
+}
+
+func TestReadArray(t *testing.T) {
+	b := NewBuffer("array", []byte("ab\x00\x00cd"))
+	data, err := b.ReadArray(4)
+	if err != nil || !slices.Equal(data, []byte("ab\x00\x00")) {
+		t.Fatalf("ReadArray = %q, %v", data, err)
+	}
+	if _, err := b.ReadArray(3); err != io.ErrUnexpectedEOF {
+		t.Fatalf("ReadArray past the end = %v, want io.ErrUnexpectedEOF", err)
+	}
+	if b.Tell() != 4 {
+		t.Fatalf("failed ReadArray advanced to %d", b.Tell())
+	}
+	if data, err := b.ReadArray(2); err != nil || string(data) != "cd" {
+		t.Fatalf("exact ReadArray = %q, %v", data, err)
+	}
+}
@@ -0,0 +1,179 @@
+package crunchio
+
+import (
+	"fmt"
+	"io"
+)
+
+// The bit cursor addresses bits within the byte at the current offset, from
+// the most significant bit (0) to the least significant bit (7). Seek always
+// leaves it byte-aligned, and byte-level reads and writes made while it is
+// mid-byte first skip ahead to the next byte boundary.
+
+// SeekBit moves the cursor to an absolute bit position in the buffer
+func (b *Buffer) SeekBit(bit int64) error {
+	if b == nil {
+		panic("SEEKBIT: buffer is nil")
+	}
+	b.Lock()
+	defer b.Unlock()
+	if b.Closed() {
+		return fmt.Errorf("buffer: seekbit: %w", ErrClosed)
+	}
+	if bit < 0 {
+		return fmt.Errorf("buffer: seekbit: negative offset %d", bit)
+	}
+	b.offset = bit / 8
+	b.bit = bit % 8
+	return nil
+}
+
+// TellBit returns the absolute bit position of the cursor
+func (b *Buffer) TellBit() int64 {
+	if b == nil {
+		panic("TELLBIT: buffer is nil")
+	}
+	b.Lock()
+	defer b.Unlock()
+	return b.offset*8 + b.bit
+}
+
+// alignBit moves a mid-byte cursor to the next byte boundary, the caller must
+// hold the lock
+func (b *Buffer) alignBit() {
+	if b.bit != 0 {
+		b.offset++
+		b.bit = 0
+	}
+}
+
+// ReadBit reads the bit at the cursor and advances past it
+func (b *Buffer) ReadBit() (bool, error) {
+	if b == nil {
+		panic("READBIT: buffer is nil")
+	}
+	v, err := b.ReadBits(1)
+	return v != 0, err
+}
+
+// WriteBit writes a single bit at the cursor and advances past it
+func (b *Buffer) WriteBit(v bool) error {
+	if b == nil {
+		panic("WRITEBIT: buffer is nil")
+	}
+	var bit uint64
+	if v {
+		bit = 1
+	}
+	return b.WriteBits(bit, 1)
+}
+
+// ReadBits reads n bits (up to 64) at the cursor, most significant first, and
+// advances past them, returning io.ErrUnexpectedEOF without advancing if
+// fewer remain
+func (b *Buffer) ReadBits(n int) (uint64, error) {
+	if b == nil {
+		panic("READBITS: buffer is nil")
+	}
+	if n < 0 || n > 64 {
+		return 0, fmt.Errorf("buffer: readbits: bit count %d out of range [0, 64]", n)
+	}
+	b.Lock()
+	defer b.Unlock()
+	if b.Closed() {
+		return 0, fmt.Errorf("buffer: readbits: %w", ErrClosed)
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	span := make([]byte, (b.bit+int64(n)+7)/8)
+	var read int
+	var err error
+	if b.parent != nil {
+		window, from := b.windowRead(span, b.offset)
+		read, err = b.parent.readOffset(window, from)
+	} else {
+		read, err = b.readOffsetLocked(span, b.offset)
+	}
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+	if read < len(span) {
+		if read == 0 && b.bit == 0 {
+			return 0, io.EOF
+		}
+		return 0, io.ErrUnexpectedEOF
+	}
+	var v uint64
+	for i := int64(0); i < int64(n); i++ {
+		at := b.bit + i
+		v = v<<1 | uint64(span[at/8]>>(7-at%8)&1)
+	}
+	b.advanceBits(int64(n))
+	return v, nil
+}
+
+// WriteBits writes the low n bits (up to 64) of v at the cursor, most
+// significant first, and advances past them, growing the buffer with zero
+// bits as needed
+func (b *Buffer) WriteBits(v uint64, n int) error {
+	if b == nil {
+		panic("WRITEBITS: buffer is nil")
+	}
+	if n < 0 || n > 64 {
+		return fmt.Errorf("buffer: writebits: bit count %d out of range [0, 64]", n)
+	}
+	b.Lock()
+	defer b.Unlock()
+	if b.Closed() {
+		return fmt.Errorf("buffer: writebits: %w", ErrClosed)
+	}
+	if b.readOnly {
+		return fmt.Errorf("buffer: writebits: %w", ErrReadOnly)
+	}
+	if n == 0 {
+		return nil
+	}
+	span := make([]byte, (b.bit+int64(n)+7)/8)
+	var err error
+	b.unshare()
+	if b.parent != nil {
+		window, from := b.windowRead(span, b.offset)
+		_, err = b.parent.readOffset(window, from)
+	} else {
+		_, err = b.readOffsetLocked(span, b.offset)
+	}
+	if err != nil && err != io.EOF {
+		return err
+	}
+	for i := int64(0); i < int64(n); i++ {
+		at := b.bit + i
+		mask := byte(1) << (7 - at%8)
+		if v>>(int64(n)-1-i)&1 != 0 {
+			span[at/8] |= mask
+		} else {
+			span[at/8] &^= mask
+		}
+	}
+	if b.parent != nil {
+		var to int64
+		if to, err = b.windowWrite("writebits", len(span), b.offset); err == nil {
+			_, err = b.parent.writeOffset(span, to)
+		}
+	} else {
+		_, err = b.writeOffsetLocked(span, b.offset)
+	}
+	if err != nil {
+		return err
+	}
+	b.advanceBits(int64(n))
+	return nil
+}
+
+// advanceBits moves the cursor forward by n bits, the caller must hold the
+// lock
+func (b *Buffer) advanceBits(n int64) {
+	bit := b.bit + n
+	b.offset += bit / 8
+	b.bit = bit % 8
+}
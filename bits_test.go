@@ -0,0 +1,113 @@
+package crunchio
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestBitsSeek(t *testing.T) {
+	b := NewBuffer("bits", []byte{0b10110010, 0b01111000, 0xAB})
+	if err := b.SeekBit(3); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := b.ReadBits(7); v != 0b1001001 || err != nil {
+		t.Fatalf("ReadBits(7) across a byte = %b, %v", v, err)
+	}
+	if b.TellBit() != 10 || b.Tell() != 1 {
+		t.Fatalf("TellBit, Tell = %d, %d, want 10, 1", b.TellBit(), b.Tell())
+	}
+	if c, err := b.ReadByte(); c != 0xAB || err != nil {
+		t.Fatalf("ReadByte mid-byte = %x, %v, want the next aligned byte", c, err)
+	}
+	if b.TellBit() != 24 {
+		t.Fatalf("TellBit after aligned ReadByte = %d, want 24", b.TellBit())
+	}
+
+	b.SeekBit(13)
+	if pos, err := b.Seek(0, io.SeekStart); pos != 0 || err != nil || b.TellBit() != 0 {
+		t.Fatalf("Seek = %d, %v, TellBit %d, want a byte-aligned cursor", pos, err, b.TellBit())
+	}
+	if v, err := b.ReadBits(4); v != 0b1011 || err != nil {
+		t.Fatalf("ReadBits(4) after Seek = %b, %v", v, err)
+	}
+	b.Seek(2, io.SeekCurrent)
+	if b.TellBit() != 16 {
+		t.Fatalf("SeekCurrent mid-byte left TellBit at %d, want 16", b.TellBit())
+	}
+	if err := b.SeekBit(-1); err == nil {
+		t.Fatal("SeekBit(-1) succeeded")
+	}
+}
+
+func TestBitsWrite(t *testing.T) {
+	b := NewBuffer("bits")
+	b.WriteBits(0b101, 3)
+	b.WriteBit(true)
+	b.Write([]byte{0xFF})
+	b.SeekBit(20)
+	b.WriteBits(0b11, 2)
+	if got, want := b.Bytes(), []byte{0b10110000, 0xFF, 0b00001100}; !slices.Equal(got, want) {
+		t.Fatalf("bit writes left %08b, want %08b", got, want)
+	}
+	b.SeekBit(22)
+	if _, err := b.ReadBits(4); err != io.ErrUnexpectedEOF {
+		t.Fatalf("ReadBits past the end = %v, want io.ErrUnexpectedEOF", err)
+	}
+	if b.TellBit() != 22 {
+		t.Fatalf("failed ReadBits moved the cursor to %d", b.TellBit())
+	}
+}
+
+func TestReadBit(t *testing.T) {
+	b := NewBuffer("bits", []byte{0b10100000, 0b00000001})
+	var got []bool
+	for {
+		v, err := b.ReadBit()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, v)
+	}
+	want := make([]bool, 16)
+	want[0], want[2], want[15] = true, true, true
+	if !slices.Equal(got, want) {
+		t.Fatalf("ReadBit read %v, want %v", got, want)
+	}
+	if b.TellBit() != 16 || b.Tell() != 2 {
+		t.Fatalf("TellBit, Tell after reading every bit = %d, %d", b.TellBit(), b.Tell())
+	}
+
+	w := NewBuffer("bits")
+	for _, v := range []bool{true, false, true, true} {
+		if err := w.WriteBit(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w.SeekBit(1)
+	if v, err := w.ReadBit(); v || err != nil {
+		t.Fatalf("ReadBit of a written 0 = %v, %v", v, err)
+	}
+	if v, err := w.ReadBit(); !v || err != nil {
+		t.Fatalf("ReadBit of a written 1 = %v, %v", v, err)
+	}
+
+	s := NewBuffer("bits", []byte{0x00, 0xFF, 0x00}).Slice(1, 1)
+	for i := 0; i < 8; i++ {
+		if v, err := s.ReadBit(); !v || err != nil {
+			t.Fatalf("ReadBit %d in a slice = %v, %v", i, v, err)
+		}
+	}
+	if _, err := s.ReadBit(); err != io.EOF {
+		t.Fatalf("ReadBit past the window = %v, want io.EOF", err)
+	}
+
+	b.Close()
+	if _, err := b.ReadBit(); !errors.Is(err, ErrClosed) {
+		t.Fatalf("ReadBit on a closed buffer = %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package crunchio
+
+import (
+	"fmt"
+)
+
+// ReadByte reads the byte at the current offset and advances past it,
+// implementing io.ByteReader
+func (b *Buffer) ReadByte() (byte, error) {
+	if b == nil {
+		panic("READBYTE: buffer is nil")
+	}
+	var c [1]byte
+	if err := b.readExact(c[:]); err != nil {
+		return 0, err
+	}
+	return c[0], nil
+}
+
+// WriteByte writes c at the current offset and advances past it, implementing
+// io.ByteWriter
+func (b *Buffer) WriteByte(c byte) error {
+	if b == nil {
+		panic("WRITEBYTE: buffer is nil")
+	}
+	_, err := b.Write([]byte{c})
+	return err
+}
+
+// UnreadByte moves the offset back one byte, implementing io.ByteScanner
+func (b *Buffer) UnreadByte() error {
+	if b == nil {
+		panic("UNREADBYTE: buffer is nil")
+	}
+	b.Lock()
+	defer b.Unlock()
+	if b.Closed() {
+		return fmt.Errorf("buffer: unreadbyte: %w", ErrClosed)
+	}
+	b.alignBit()
+	if b.offset <= 0 {
+		return fmt.Errorf("buffer: unreadbyte: at beginning of buffer")
+	}
+	b.offset--
+	return nil
+}
+
+// ReadByteAt reads the single byte at offset without moving the offset,
+// returning io.EOF if offset is past the end
+func (b *Buffer) ReadByteAt(offset int64) (byte, error) {
+	if b == nil {
+		panic("READBYTEAT: buffer is nil")
+	}
+	var c [1]byte
+	if err := b.readExactAt(c[:], offset); err != nil {
+		return 0, err
+	}
+	b.afterRead(offset, c[:])
+	return c[0], nil
+}
+
+// WriteByteAt writes the single byte c at offset without moving the offset
+func (b *Buffer) WriteByteAt(offset int64, c byte) error {
+	if b == nil {
+		panic("WRITEBYTEAT: buffer is nil")
+	}
+	_, err := b.WriteOffset([]byte{c}, offset)
+	return err
+}
@@ -0,0 +1,62 @@
+package crunchio
+
+import (
+	"io"
+	"testing"
+)
+
+var _ io.ByteScanner = (*Buffer)(nil)
+
+func TestByteAt(t *testing.T) {
+	b := NewBuffer("byte", []byte("header body"))
+	b.Seek(4, io.SeekStart)
+	for _, tt := range []struct {
+		offset int64
+		old    byte
+		new    byte
+	}{
+		{0, 'h', 'H'},
+		{6, ' ', '_'},
+		{10, 'y', 'Y'},
+	} {
+		if c, err := b.ReadByteAt(tt.offset); c != tt.old || err != nil {
+			t.Fatalf("ReadByteAt(%d) = %q, %v, want %q", tt.offset, c, err, tt.old)
+		}
+		if err := b.WriteByteAt(tt.offset, tt.new); err != nil {
+			t.Fatalf("WriteByteAt(%d) = %v", tt.offset, err)
+		}
+		if c, _ := b.ReadByteAt(tt.offset); c != tt.new {
+			t.Fatalf("ReadByteAt(%d) after a write = %q, want %q", tt.offset, c, tt.new)
+		}
+	}
+	if got := b.String(); got != "Header_bodY" || b.Tell() != 4 {
+		t.Fatalf("byte patches left %q at offset %d", got, b.Tell())
+	}
+	if _, err := b.ReadByteAt(11); err != io.EOF {
+		t.Fatalf("ReadByteAt past the end = %v, want io.EOF", err)
+	}
+	if _, err := b.ReadByteAt(-1); err == nil {
+		t.Fatal("ReadByteAt(-1) succeeded")
+	}
+	if b.Size() != 11 {
+		t.Fatalf("ReadByteAt changed the length to %d", b.Size())
+	}
+}
+
+func TestReadUnreadByte(t *testing.T) {
+	b := NewBuffer("byte", []byte("ab"))
+	if err := b.UnreadByte(); err == nil {
+		t.Fatal("UnreadByte at the start succeeded")
+	}
+	c, _ := b.ReadByte()
+	if err := b.UnreadByte(); err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := b.ReadByte(); again != c || c != 'a' {
+		t.Fatalf("ReadByte after UnreadByte = %q, want %q", again, c)
+	}
+	b.ReadByte()
+	if _, err := b.ReadByte(); err != io.EOF {
+		t.Fatalf("ReadByte at the end = %v, want io.EOF", err)
+	}
+}
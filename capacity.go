@@ -0,0 +1,286 @@
+package crunchio
+
+import (
+	"fmt"
+	"math/bits"
+
+	crunch "github.com/superwhiskers/crunch/v3"
+)
+
+// Growth selects how the backing crunch buffer grows when a write runs past
+// its capacity
+type Growth int
+
+const (
+	// GrowthExact grows the backing by exactly the shortfall
+	GrowthExact Growth = iota
+	// GrowthDoubling grows the backing to the next power of two, amortizing
+	// reallocations across many small appends
+	GrowthDoubling
+)
+
+// next returns the capacity to grow to in order to fit need bytes
+func (g Growth) next(need int64) int64 {
+	if g != GrowthDoubling || need <= 0 {
+		return need
+	}
+	return int64(1) << bits.Len64(uint64(need-1))
+}
+
+// SetGrowth sets the growth strategy of the buffer that owns the bytes
+func (b *Buffer) SetGrowth(growth Growth) {
+	if b == nil {
+		panic("SETGROWTH: buffer is nil")
+	}
+	root := b.root()
+	root.Lock()
+	defer root.Unlock()
+	root.growth = growth
+}
+
+// Cap returns the number of bytes held by the backing crunch buffer, which may
+// exceed the logical length reported by ByteCapacity
+func (b *Buffer) Cap() int64 {
+	if b == nil {
+		panic("CAP: buffer is nil")
+	}
+	b.Lock()
+	defer b.Unlock()
+	root := b.root()
+	if root.file != nil {
+		return root.length
+	}
+	return root.capacity
+}
+
+// Shrink reallocates the backing crunch buffer to exactly the logical length,
+// releasing any spare capacity
+func (b *Buffer) Shrink() error {
+	if b == nil {
+		panic("SHRINK: buffer is nil")
+	}
+	b.Lock()
+	defer b.Unlock()
+	if b.Closed() {
+		return fmt.Errorf("buffer: shrink: %w", ErrClosed)
+	}
+	if b.readOnly {
+		return fmt.Errorf("buffer: shrink: %w", ErrReadOnly)
+	}
+	b.unshare()
+	if b.parent != nil {
+		return b.parent.Shrink()
+	}
+	buffer := b.Buffer()
+	if buffer == nil {
+		return fmt.Errorf("buffer: shrink: crunch buffer vanished")
+	}
+	if b.capacity == b.length {
+		return nil
+	}
+	data := make([]byte, b.length)
+	if b.length > 0 {
+		copy(data, buffer.ReadBytes(0, b.length))
+	}
+	b.setBacking(crunch.NewBuffer(data))
+	return nil
+}
+
+// Available returns how many bytes can be written past the logical end before
+// the backing crunch buffer has to grow
+func (b *Buffer) Available() int64 {
+	if b == nil {
+		panic("AVAILABLE: buffer is nil")
+	}
+	b.Lock()
+	defer b.Unlock()
+	root := b.root()
+	if root.file != nil {
+		return 0
+	}
+	return root.capacity - root.length
+}
+
+// EnsureCapacity grows the backing crunch buffer to hold at least n bytes
+// without changing the logical length
+func (b *Buffer) EnsureCapacity(n int64) error {
+	if b == nil {
+		panic("ENSURECAPACITY: buffer is nil")
+	}
+	b.Lock()
+	defer b.Unlock()
+	if b.Closed() {
+		return fmt.Errorf("buffer: ensurecapacity: %w", ErrClosed)
+	}
+	if b.readOnly {
+		return fmt.Errorf("buffer: ensurecapacity: %w", ErrReadOnly)
+	}
+	b.unshare()
+	if b.parent != nil {
+		return b.parent.EnsureCapacity(n)
+	}
+	if err := b.checkLimit("ensurecapacity", n); err != nil {
+		return err
+	}
+	buffer := b.Buffer()
+	if buffer == nil {
+		return fmt.Errorf("buffer: ensurecapacity: crunch buffer vanished")
+	}
+	if toGrow := n - b.capacity; toGrow > 0 {
+		b.growBacking(buffer, toGrow)
+	}
+	return nil
+}
+
+// SetLimit caps the logical length of the buffer that owns the bytes at n,
+// operations that would grow it further failing with ErrTooLarge, 0 removes
+// the limit
+func (b *Buffer) SetLimit(n int64) {
+	if b == nil {
+		panic("SETLIMIT: buffer is nil")
+	}
+	root := b.root()
+	root.Lock()
+	defer root.Unlock()
+	root.limit = max(n, 0)
+}
+
+// sizeLimit returns the limit set on the buffer that owns the bytes
+func (b *Buffer) sizeLimit() int64 {
+	root := b.root()
+	root.RLock()
+	defer root.RUnlock()
+	return root.limit
+}
+
+// checkLimit reports whether growing the buffer to end bytes would exceed its
+// limit, the caller must hold the lock of the buffer that owns the bytes
+func (b *Buffer) checkLimit(op string, end int64) error {
+	if b.limit > 0 && end > b.limit && end > b.length {
+		return fmt.Errorf("buffer: %s: %d bytes exceeds limit %d: %w", op, end, b.limit, ErrTooLarge)
+	}
+	return nil
+}
+
+// SetCapacityWatcher sets a function called with the old and new capacity
+// whenever the backing crunch buffer of the buffer that owns the bytes grows or
+// shrinks, after the lock is released and with every change made while it was
+// held folded into one call, passing nil removes it
+func (b *Buffer) SetCapacityWatcher(watcher func(oldCap, newCap int64)) {
+	if b == nil {
+		panic("SETCAPACITYWATCHER: buffer is nil")
+	}
+	root := b.root()
+	root.Lock()
+	defer root.Unlock()
+	root.capacityWatcher = watcher
+	root.watchedCapacity = root.capacity
+}
+
+// Unlock releases the buffer's write lock, then reports any capacity change
+// made while it was held to the capacity watcher
+func (b *Buffer) Unlock() {
+	watcher := b.capacityWatcher
+	if watcher == nil || b.capacity == b.watchedCapacity {
+		b.RWMutex.Unlock()
+		return
+	}
+	oldCap, newCap := b.watchedCapacity, b.capacity
+	b.watchedCapacity = newCap
+	b.RWMutex.Unlock()
+	watcher(oldCap, newCap)
+}
+
+// setBacking installs buffer as the backing crunch buffer, caching its
+// capacity
+func (b *Buffer) setBacking(buffer *crunch.Buffer) {
+	b.buffer = buffer
+	b.capacity = buffer.ByteCapacity()
+}
+
+// growBacking grows the backing crunch buffer by n bytes, keeping the cached
+// capacity in sync
+func (b *Buffer) growBacking(buffer *crunch.Buffer, n int64) {
+	buffer.Grow(n)
+	b.capacity = buffer.ByteCapacity()
+}
+
+// Extend makes room for n bytes at the current offset, advances past them and
+// returns a slice aliasing them so the caller can fill them in place
+//
+// Bytes past the previous end of the buffer start out zeroed. The slice is
+// only valid until the next operation that grows, shrinks or resets the
+// buffer, and writes made through it bypass hooks and hashing.
+func (b *Buffer) Extend(n int64) ([]byte, error) {
+	if b == nil {
+		panic("EXTEND: buffer is nil")
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("buffer: extend: negative length %d", n)
+	}
+	b.Lock()
+	defer b.Unlock()
+	if b.Closed() {
+		return nil, fmt.Errorf("buffer: extend: %w", ErrClosed)
+	}
+	if b.readOnly {
+		return nil, fmt.Errorf("buffer: extend: %w", ErrReadOnly)
+	}
+	b.alignBit()
+	var data []byte
+	var err error
+	b.unshare()
+	if b.parent != nil {
+		var at int64
+		if at, err = b.windowWrite("extend", int(n), b.offset); err == nil {
+			data, err = b.parent.extendAt(at, n)
+		}
+	} else {
+		data, err = b.extendAtLocked(b.offset, n)
+	}
+	if err == nil {
+		b.offset += n
+	}
+	return data, err
+}
+
+func (b *Buffer) extendAt(offset, n int64) ([]byte, error) {
+	b.Lock()
+	defer b.Unlock()
+	if b.readOnly {
+		return nil, fmt.Errorf("buffer: extend: %w", ErrReadOnly)
+	}
+	b.unshare()
+	if b.parent != nil {
+		at, err := b.windowWrite("extend", int(n), offset)
+		if err != nil {
+			return nil, err
+		}
+		return b.parent.extendAt(at, n)
+	}
+	return b.extendAtLocked(offset, n)
+}
+
+func (b *Buffer) extendAtLocked(offset, n int64) ([]byte, error) {
+	if offset < 0 {
+		return nil, fmt.Errorf("buffer: extend: negative offset %d", offset)
+	}
+	if b.file != nil {
+		return nil, fmt.Errorf("buffer: extend: file backings cannot be aliased")
+	}
+	buffer := b.Buffer()
+	if buffer == nil {
+		return nil, fmt.Errorf("buffer: extend: crunch buffer vanished")
+	}
+	if err := b.checkLimit("extend", offset+n); err != nil {
+		return nil, err
+	}
+	end := b.length
+	b.grow(buffer, offset, n)
+	b.writes.Add(1)
+	data := buffer.Bytes()[offset : offset+n : offset+n]
+	if end < offset+n {
+		clear(data[max(end-offset, 0):])
+	}
+	return data, nil
+}
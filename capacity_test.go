@@ -0,0 +1,240 @@
+package crunchio
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestShrink(t *testing.T) {
+	b := NewBuffer("shrink")
+	b.Write(bytes.Repeat([]byte{1}, 1000))
+	if err := b.Truncate(10); err != nil {
+		t.Fatal(err)
+	}
+	if b.Cap() < 1000 {
+		t.Fatalf("Cap after Truncate = %d, want at least 1000", b.Cap())
+	}
+	if err := b.Shrink(); err != nil {
+		t.Fatal(err)
+	}
+	if b.Cap() != b.Size() || b.Size() != 10 {
+		t.Fatalf("Cap, Size after Shrink = %d, %d, want 10, 10", b.Cap(), b.Size())
+	}
+	if got := b.Bytes(); !bytes.Equal(got, bytes.Repeat([]byte{1}, 10)) {
+		t.Fatalf("Shrink changed the bytes to % x", got)
+	}
+	if err := b.Reference().Shrink(); err != nil || b.Cap() != 10 {
+		t.Fatalf("Shrink through a reference = %v, Cap %d", err, b.Cap())
+	}
+}
+
+func TestAvailable(t *testing.T) {
+	b := NewBuffer("available")
+	if err := b.EnsureCapacity(16); err != nil {
+		t.Fatal(err)
+	}
+	if b.Available() != 16 {
+		t.Fatalf("Available after EnsureCapacity(16) = %d", b.Available())
+	}
+	for want := int64(12); want >= 0; want -= 4 {
+		b.Write([]byte("abcd"))
+		if b.Available() != want {
+			t.Fatalf("Available after %d bytes = %d, want %d", b.Size(), b.Available(), want)
+		}
+	}
+	b.Write([]byte("e"))
+	if b.Available() != b.Cap()-17 || b.Cap() < 17 {
+		t.Fatalf("Available after a grow = %d with Cap %d", b.Available(), b.Cap())
+	}
+	if err := b.EnsureCapacity(64); err != nil || b.Available() != 64-17 {
+		t.Fatalf("Available after EnsureCapacity(64) = %d, %v", b.Available(), err)
+	}
+	if got := b.Reference().Available(); got != b.Available() {
+		t.Fatalf("reference sees %d available, want %d", got, b.Available())
+	}
+}
+
+func TestGrowthDoubling(t *testing.T) {
+	for _, tt := range []struct{ need, want int64 }{{0, 0}, {1, 1}, {3, 4}, {4, 4}, {5, 8}, {1000, 1024}} {
+		if got := GrowthDoubling.next(tt.need); got != tt.want {
+			t.Fatalf("GrowthDoubling.next(%d) = %d, want %d", tt.need, got, tt.want)
+		}
+		if got := GrowthExact.next(tt.need); got != tt.need {
+			t.Fatalf("GrowthExact.next(%d) = %d", tt.need, got)
+		}
+	}
+	b := NewBuffer("growth")
+	b.SetGrowth(GrowthDoubling)
+	for i := 0; i < 1000; i++ {
+		b.WriteByte(byte(i))
+	}
+	if b.Size() != 1000 || b.Cap() != 1024 {
+		t.Fatalf("Size, Cap after 1000 appends = %d, %d, want 1000, 1024", b.Size(), b.Cap())
+	}
+	for i, c := range b.Bytes() {
+		if c != byte(i) {
+			t.Fatalf("byte %d = %d after doubling growth", i, c)
+		}
+	}
+}
+
+// benchmarkAppends appends 10k single bytes, reporting how often the backing
+// had to grow alongside the allocations
+func benchmarkAppends(b *testing.B, growth Growth) {
+	p := []byte{0}
+	grows := 0
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buffer := NewBuffer("growth")
+		buffer.SetGrowth(growth)
+		buffer.SetCapacityWatcher(func(oldCap, newCap int64) { grows++ })
+		for j := 0; j < 10000; j++ {
+			buffer.Write(p)
+		}
+	}
+	b.ReportMetric(float64(grows)/float64(b.N), "grows/op")
+}
+
+func BenchmarkAppendGrowthExact(b *testing.B)    { benchmarkAppends(b, GrowthExact) }
+func BenchmarkAppendGrowthDoubling(b *testing.B) { benchmarkAppends(b, GrowthDoubling) }
+
+func TestExtend(t *testing.T) {
+	b := NewBuffer("extend", []byte("head"))
+	b.Seek(0, io.SeekEnd)
+	data, err := b.Extend(6)
+	if err != nil || len(data) != 6 || cap(data) != 6 {
+		t.Fatalf("Extend = len %d, cap %d, %v", len(data), cap(data), err)
+	}
+	if !bytes.Equal(data, make([]byte, 6)) {
+		t.Fatalf("Extend past the end returned % x, want zeroes", data)
+	}
+	copy(data, "-body!")
+	if b.Tell() != 10 || b.Size() != 10 {
+		t.Fatalf("Extend left the offset at %d and length at %d", b.Tell(), b.Size())
+	}
+	b.Seek(0, io.SeekStart)
+	got, err := io.ReadAll(b)
+	if err != nil || string(got) != "head-body!" {
+		t.Fatalf("read back %q, %v", got, err)
+	}
+
+	b.Seek(2, io.SeekStart)
+	data, _ = b.Extend(2)
+	if string(data) != "ad" {
+		t.Fatalf("Extend over existing bytes returned %q, want them untouched", data)
+	}
+	if _, err := b.Extend(-1); err == nil {
+		t.Fatal("Extend(-1) succeeded")
+	}
+	file, _ := newTempFileBuffer(t, "")
+	if _, err := file.Extend(1); err == nil {
+		t.Fatal("Extend on a file backing succeeded")
+	}
+}
+
+func TestCapacityWatcher(t *testing.T) {
+	b := NewBuffer("watcher", []byte("abcd"))
+	type change struct{ oldCap, newCap int64 }
+	var changes []change
+	b.SetCapacityWatcher(func(oldCap, newCap int64) {
+		if b.TryLock() {
+			b.Unlock()
+		} else {
+			t.Error("capacity watcher called with the lock held")
+		}
+		changes = append(changes, change{oldCap, newCap})
+	})
+	b.WriteAt([]byte("ef"), 2)
+	if len(changes) != 0 {
+		t.Fatalf("write within the capacity reported %v", changes)
+	}
+	b.WriteAt([]byte("ghij"), 4)
+	if len(changes) != 1 || changes[0].oldCap != 4 || changes[0].newCap != b.Cap() || b.Cap() < 8 {
+		t.Fatalf("growing write reported %v with Cap %d, want {4 %d}", changes, b.Cap(), b.Cap())
+	}
+	grown := b.Cap()
+	b.Truncate(2)
+	b.Shrink()
+	if len(changes) != 2 || changes[1] != (change{grown, 2}) {
+		t.Fatalf("Shrink reported %v, want {%d 2}", changes[1:], grown)
+	}
+	b.Reference().EnsureCapacity(64)
+	if len(changes) != 3 || changes[2].oldCap != 2 || changes[2].newCap < 64 {
+		t.Fatalf("EnsureCapacity through a reference reported %v", changes[2:])
+	}
+	b.SetCapacityWatcher(nil)
+	b.EnsureCapacity(1024)
+	if len(changes) != 3 {
+		t.Fatalf("removed watcher saw %v", changes[3:])
+	}
+}
+
+func TestLimit(t *testing.T) {
+	for name, grow := range map[string]func(*Buffer) error{
+		"Write": func(b *Buffer) error {
+			b.Seek(0, io.SeekEnd)
+			_, err := b.Write([]byte("xyz"))
+			return err
+		},
+		"WriteAt":       func(b *Buffer) error { _, err := b.WriteAt([]byte("x"), 6); return err },
+		"WriteOffset":   func(b *Buffer) error { _, err := b.WriteOffset([]byte("xyz"), 4); return err },
+		"WriteAbstract": func(b *Buffer) error { _, err := b.WriteAbstractAt(4, uint32(1)); return err },
+		"WriteVectored": func(b *Buffer) error {
+			b.Seek(3, io.SeekStart)
+			_, err := b.WriteVectored([]byte("xy"), []byte("zw"))
+			return err
+		},
+		"WriteBits": func(b *Buffer) error {
+			b.SeekBit(6 * 8)
+			return b.WriteBits(1, 1)
+		},
+		"Insert":  func(b *Buffer) error { return b.Insert(2, []byte("xyz")) },
+		"Fill":    func(b *Buffer) error { return b.Fill(4, 3, 'x') },
+		"Merge":   func(b *Buffer) error { return b.Merge(NewBuffer("other", []byte("xyz"))) },
+		"Reserve": func(b *Buffer) error { _, err := b.Reserve(3); return err },
+		"Extend": func(b *Buffer) error {
+			b.Seek(0, io.SeekEnd)
+			_, err := b.Extend(3)
+			return err
+		},
+		"Replace":  func(b *Buffer) error { _, err := b.Replace([]byte("b"), []byte("wxyz"), -1); return err },
+		"CopyInto": func(b *Buffer) error { return NewBuffer("src", []byte("1234567")).CopyInto(b) },
+		"ReadFrom": func(b *Buffer) error {
+			b.Seek(0, io.SeekEnd)
+			_, err := b.ReadFrom(bytes.NewReader([]byte("xyz")))
+			return err
+		},
+		"EnsureCapacity": func(b *Buffer) error { return b.EnsureCapacity(7) },
+		"Reference.Write": func(b *Buffer) error {
+			_, err := b.Reference().WriteAt([]byte("xyz"), 4)
+			return err
+		},
+	} {
+		b := NewBuffer("limit", []byte("abcd"))
+		b.Reference().SetLimit(6)
+		if err := grow(b); !errors.Is(err, ErrTooLarge) {
+			t.Fatalf("%s past the limit = %v, want ErrTooLarge", name, err)
+		}
+		if b.Size() > 6 {
+			t.Fatalf("%s grew the buffer to %d bytes past the limit", name, b.Size())
+		}
+	}
+
+	b := NewBuffer("limit", []byte("abcd"))
+	b.SetLimit(6)
+	if n, err := b.WriteAt([]byte("ef"), 4); n != 2 || err != nil {
+		t.Fatalf("WriteAt up to the limit = %d, %v", n, err)
+	}
+	if n, err := b.WriteAt([]byte("EF"), 4); n != 2 || err != nil {
+		t.Fatalf("overwrite at the limit = %d, %v", n, err)
+	}
+	if b.Swap([]byte("1234567")) != nil || b.String() != "abcdEF" {
+		t.Fatalf("Swap past the limit replaced the bytes with %q", b.String())
+	}
+	b.SetLimit(0)
+	if _, err := b.WriteAt([]byte("gh"), 6); err != nil || b.Size() != 8 {
+		t.Fatalf("WriteAt after removing the limit = %v, Size %d", err, b.Size())
+	}
+}
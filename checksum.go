@@ -0,0 +1,81 @@
+package crunchio
+
+import (
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+)
+
+// ErrChecksum is returned when stored and computed checksums disagree
+var ErrChecksum = errors.New("checksum mismatch")
+
+// ExpectTrailerCRC32 makes Close verify that the last 4 bytes of the buffer
+// hold the CRC32 (IEEE) of every byte before them, stored in the buffer's
+// byte order
+func (b *Buffer) ExpectTrailerCRC32() {
+	if b == nil {
+		panic("EXPECTTRAILERCRC32: buffer is nil")
+	}
+	b.Lock()
+	defer b.Unlock()
+	b.trailerCRC32 = true
+}
+
+// verifyTrailerCRC32 checks the CRC32 trailer, the caller must hold the lock
+func (b *Buffer) verifyTrailerCRC32() error {
+	data := b.snapshot()
+	if len(data) < 4 {
+		return fmt.Errorf("buffer: close: %d bytes is too short for a CRC32 trailer", len(data))
+	}
+	body, trailer := data[:len(data)-4], data[len(data)-4:]
+	stored := b.byteOrder().Uint32(trailer)
+	if computed := crc32.ChecksumIEEE(body); computed != stored {
+		return fmt.Errorf("buffer: close: crc32 %08x does not match trailer %08x: %w", computed, stored, ErrChecksum)
+	}
+	return nil
+}
+
+// SetHasher feeds every byte subsequently appended to the buffer into h, or
+// stops hashing when h is nil
+//
+// Only sequential appends are hashed: a write is fed to h when it starts
+// exactly where the previously hashed data ended, beginning at the length of
+// the buffer when SetHasher is called. Writes anywhere else, including
+// overwrites of already hashed bytes, are not hashed, so Sum only matches the
+// contents for buffers built strictly by appending. Writes are fed to h while
+// the buffer is still locked, so concurrent appends are hashed in the order
+// they land.
+func (b *Buffer) SetHasher(h hash.Hash) {
+	if b == nil {
+		panic("SETHASHER: buffer is nil")
+	}
+	b.Lock()
+	defer b.Unlock()
+	b.hasher = h
+	b.hashEnd = b.lengthLocked()
+}
+
+// hash feeds data written at offset to the hasher set by SetHasher when it
+// continues the hashed data, the caller must hold the lock so that appends are
+// hashed in the order they land
+func (b *Buffer) hash(offset int64, data []byte) {
+	if b.hasher != nil && offset == b.hashEnd {
+		b.hasher.Write(data)
+		b.hashEnd += int64(len(data))
+	}
+}
+
+// Sum returns the current digest of the hasher set by SetHasher, or nil if
+// there is none
+func (b *Buffer) Sum() []byte {
+	if b == nil {
+		panic("SUM: buffer is nil")
+	}
+	b.RLock()
+	defer b.RUnlock()
+	if b.hasher == nil {
+		return nil
+	}
+	return b.hasher.Sum(nil)
+}
@@ -0,0 +1,107 @@
+package crunchio
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"sync"
+	"testing"
+)
+
+// withTrailer appends the CRC32 of body to it in order
+func withTrailer(body []byte, order binary.AppendByteOrder) []byte {
+	return order.AppendUint32(append([]byte{}, body...), crc32.ChecksumIEEE(body))
+}
+
+func TestTrailerCRC32(t *testing.T) {
+	body := []byte("checksummed payload")
+	b := NewBuffer("crc", withTrailer(body, binary.LittleEndian))
+	b.ExpectTrailerCRC32()
+	if err := b.Close(); err != nil {
+		t.Fatalf("Close with a matching trailer = %v", err)
+	}
+
+	b = NewBuffer("crc", withTrailer(body, binary.BigEndian))
+	b.SetByteOrder(binary.BigEndian)
+	b.ExpectTrailerCRC32()
+	if err := b.Close(); err != nil {
+		t.Fatalf("Close with a matching big-endian trailer = %v", err)
+	}
+}
+
+func TestTrailerCRC32Corrupted(t *testing.T) {
+	data := withTrailer([]byte("checksummed payload"), binary.LittleEndian)
+	data[3] ^= 0xFF
+	b := NewBuffer("crc", data)
+	b.ExpectTrailerCRC32()
+	if err := b.Close(); !errors.Is(err, ErrChecksum) {
+		t.Fatalf("Close with a corrupted body = %v, want ErrChecksum", err)
+	}
+
+	b = NewBuffer("crc", []byte{1, 2, 3})
+	b.ExpectTrailerCRC32()
+	if err := b.Close(); err == nil || errors.Is(err, ErrChecksum) {
+		t.Fatalf("Close of a buffer too short for a trailer = %v", err)
+	}
+
+	b = NewBuffer("crc", data)
+	if err := b.Close(); err != nil {
+		t.Fatalf("Close without ExpectTrailerCRC32 = %v", err)
+	}
+}
+
+func TestHasher(t *testing.T) {
+	b := NewBuffer("hash")
+	b.SetHasher(sha256.New())
+	b.Write([]byte("first "))
+	b.WriteString("second ")
+	b.WriteAbstract(uint16(3))
+	b.WriteVectored([]byte("four"), []byte("five"))
+	want := sha256.Sum256(b.Bytes())
+	if got := b.Sum(); !bytes.Equal(got, want[:]) {
+		t.Fatalf("Sum = %x, want %x", got, want)
+	}
+	b.WriteAt([]byte("X"), 0)
+	if got := b.Sum(); !bytes.Equal(got, want[:]) {
+		t.Fatalf("an overwrite changed Sum to %x", got)
+	}
+	b.SetHasher(nil)
+	if b.Sum() != nil {
+		t.Fatal("Sum without a hasher is not nil")
+	}
+}
+
+func TestHasherStartsAtEnd(t *testing.T) {
+	b := NewBuffer("hash", []byte("existing"))
+	b.Seek(0, io.SeekEnd)
+	b.SetHasher(sha256.New())
+	b.Write([]byte("appended"))
+	want := sha256.Sum256([]byte("appended"))
+	if got := b.Sum(); !bytes.Equal(got, want[:]) {
+		t.Fatalf("Sum = %x, want %x", got, want)
+	}
+}
+
+func TestHasherConcurrentAppends(t *testing.T) {
+	b := NewBuffer("hash")
+	b.SetHasher(sha256.New())
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(c byte) {
+			defer wg.Done()
+			part := NewBuffer("part", bytes.Repeat([]byte{c}, 16))
+			for j := 0; j < 100; j++ {
+				b.Merge(part)
+			}
+		}(byte('a' + i))
+	}
+	wg.Wait()
+	want := sha256.Sum256(b.Bytes())
+	if got := b.Sum(); !bytes.Equal(got, want[:]) {
+		t.Fatalf("Sum after concurrent appends = %x, want %x", got, want)
+	}
+}
@@ -0,0 +1,110 @@
+package crunchio
+
+import "bytes"
+
+// compareChunk is the size of the chunks Compare reads from each side
+const compareChunk = 4096
+
+// Equal reports whether the contents of b equal other, which may be a *Buffer,
+// []byte, string or Bytes, any other value, including nil, is never equal
+func (b *Buffer) Equal(other any) bool {
+	if b == nil {
+		panic("EQUAL: buffer is nil")
+	}
+	at, _, ok := b.compare(other)
+	return ok && at < 0
+}
+
+// Compare compares the contents of b with other like bytes.Compare, returning
+// -1, 0 or 1, other may be a *Buffer, []byte, string or Bytes, b sorting after
+// any other value, including nil
+//
+// Both sides are read a chunk at a time rather than copied whole, and only one
+// buffer is locked at once, so the result is only consistent if neither side
+// is written to during the comparison. A side that can no longer be read, such
+// as a closed buffer, sorts as if it ended there.
+func (b *Buffer) Compare(other any) int {
+	if b == nil {
+		panic("COMPARE: buffer is nil")
+	}
+	_, cmp, ok := b.compare(other)
+	if !ok {
+		return 1
+	}
+	return cmp
+}
+
+// DiffOffset returns the index of the first byte at which the contents of b
+// and other differ, the length of the shorter side if one is a prefix of the
+// other, or -1 if they are equal, b differing from any value that isn't a
+// *Buffer, []byte, string or Bytes, including nil, at 0
+func (b *Buffer) DiffOffset(other any) int64 {
+	if b == nil {
+		panic("DIFFOFFSET: buffer is nil")
+	}
+	at, _, ok := b.compare(other)
+	if !ok {
+		return 0
+	}
+	return at
+}
+
+// compare returns the first differing index and the ordering of b and other,
+// ok is false if other is not a type it can compare against
+func (b *Buffer) compare(other any) (at int64, cmp int, ok bool) {
+	var otherAt func(dst []byte, offset int64) error
+	var otherLen int64
+	switch other := other.(type) {
+	case *Buffer:
+		if other == nil {
+			return 0, 0, false
+		}
+		if other == b {
+			return -1, 0, true
+		}
+		otherAt, otherLen = other.readExactAt, other.Size()
+	case []byte:
+		otherAt, otherLen = sliceAt(other), int64(len(other))
+	case string:
+		otherAt, otherLen = sliceAt(other), int64(len(other))
+	case Bytes:
+		data := other.Bytes()
+		otherAt, otherLen = sliceAt(data), int64(len(data))
+	default:
+		return 0, 0, false
+	}
+	length := b.Size()
+	var mine, theirs [compareChunk]byte
+	for at = 0; at < min(length, otherLen); at += compareChunk {
+		size := min(min(length, otherLen)-at, compareChunk)
+		if b.readExactAt(mine[:size], at) != nil {
+			return at, -1, true
+		}
+		if otherAt(theirs[:size], at) != nil {
+			return at, 1, true
+		}
+		if cmp = bytes.Compare(mine[:size], theirs[:size]); cmp != 0 {
+			for i := int64(0); i < size; i++ {
+				if mine[i] != theirs[i] {
+					return at + i, cmp, true
+				}
+			}
+		}
+	}
+	switch {
+	case length < otherLen:
+		return length, -1, true
+	case length > otherLen:
+		return otherLen, 1, true
+	}
+	return -1, 0, true
+}
+
+// sliceAt adapts data to the readExactAt signature, indexing a string in place
+// rather than copying it
+func sliceAt[T []byte | string](data T) func(dst []byte, offset int64) error {
+	return func(dst []byte, offset int64) error {
+		copy(dst, data[offset:])
+		return nil
+	}
+}
@@ -0,0 +1,88 @@
+package crunchio
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	b := NewBuffer("compare", []byte("abcdef"))
+	for _, test := range []struct {
+		other any
+		cmp   int
+		at    int64
+	}{
+		{[]byte("abcdef"), 0, -1},
+		{"abcdef", 0, -1},
+		{bytes.NewBufferString("abcdef"), 0, -1},
+		{NewBuffer("other", []byte("abcdef")), 0, -1},
+		{b, 0, -1},
+		{"abcdeg", -1, 5},
+		{"abCdef", 1, 2},
+		{"abc", 1, 3},
+		{"abcdefg", -1, 6},
+		{"", 1, 0},
+		{NewBuffer("other"), 1, 0},
+	} {
+		if cmp := b.Compare(test.other); cmp != test.cmp {
+			t.Fatalf("Compare(%v) = %d, want %d", test.other, cmp, test.cmp)
+		}
+		if equal := b.Equal(test.other); equal != (test.cmp == 0) {
+			t.Fatalf("Equal(%v) = %v", test.other, equal)
+		}
+		if at := b.DiffOffset(test.other); at != test.at {
+			t.Fatalf("DiffOffset(%v) = %d, want %d", test.other, at, test.at)
+		}
+	}
+}
+
+func TestCompareChunks(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), compareChunk/4)
+	b := NewBuffer("compare", data)
+	other := bytes.Clone(data)
+	if !b.Equal(other) {
+		t.Fatal("Equal across chunks reports a difference")
+	}
+	at := int64(compareChunk + 17)
+	other[at]++
+	if got := b.DiffOffset(other); got != at || b.Compare(other) != -1 {
+		t.Fatalf("DiffOffset in the second chunk = %d, want %d", got, at)
+	}
+	if got := NewBuffer("other", other).DiffOffset(b); got != at {
+		t.Fatalf("DiffOffset between buffers = %d, want %d", got, at)
+	}
+}
+
+func TestCompareReference(t *testing.T) {
+	b := NewBuffer("compare", []byte("shared"))
+	ref := b.Reference()
+	ref.Seek(3, io.SeekStart)
+	if !ref.Equal(b) || !b.Equal(ref) {
+		t.Fatal("a reference compares unequal to its parent")
+	}
+	b.Write([]byte("S"))
+	if !ref.Equal("Shared") {
+		t.Fatal("reference did not see a write to its parent")
+	}
+	slice := b.Slice(1, 3)
+	if !slice.Equal("har") || slice.DiffOffset("hat") != 2 {
+		t.Fatalf("slice holding %q differs from \"hat\" at %d", slice.String(), slice.DiffOffset("hat"))
+	}
+}
+
+func TestCompareUnsupported(t *testing.T) {
+	for _, b := range []*Buffer{NewBuffer("compare"), NewBuffer("compare", []byte("abc"))} {
+		for _, other := range []any{nil, (*Buffer)(nil), 42, []int{1}} {
+			if b.Equal(other) {
+				t.Fatalf("Equal(%#v) = true", other)
+			}
+			if cmp := b.Compare(other); cmp != 1 {
+				t.Fatalf("Compare(%#v) = %d, want 1", other, cmp)
+			}
+			if at := b.DiffOffset(other); at != 0 {
+				t.Fatalf("DiffOffset(%#v) = %d, want 0", other, at)
+			}
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package crunchio
+
+import (
+	"fmt"
+)
+
+// Merge appends the full contents of each other buffer to the end of b
+//
+// The contents of the other buffers are snapshotted one at a time before b is
+// locked, so no two buffer locks are ever held at once and merging a buffer
+// into itself is safe.
+func (b *Buffer) Merge(others ...*Buffer) error {
+	if b == nil {
+		panic("MERGE: buffer is nil")
+	}
+	total := int64(0)
+	for i := 0; i < len(others); i++ {
+		if others[i] == nil {
+			return fmt.Errorf("buffer: merge: buffer %d is nil", i)
+		}
+		total += others[i].Size()
+	}
+	data := make([]byte, 0, total)
+	for i := 0; i < len(others); i++ {
+		data = append(data, others[i].Bytes()...)
+	}
+	at, err := b.appendBytes(data)
+	if err == nil {
+		b.afterWrite(at, data)
+	}
+	return err
+}
+
+// appendBytes writes data at the end of b in a single grow, without moving
+// the offset, returning where it was written
+func (b *Buffer) appendBytes(data []byte) (at int64, err error) {
+	b.Lock()
+	defer b.Unlock()
+	defer func() {
+		if err == nil {
+			b.hash(at, data)
+		}
+	}()
+	if b.Closed() {
+		return 0, fmt.Errorf("buffer: merge: %w", ErrClosed)
+	}
+	if b.readOnly {
+		return 0, fmt.Errorf("buffer: merge: %w", ErrReadOnly)
+	}
+	b.unshare()
+	if b.parent != nil {
+		if err = b.windowResize("merge"); err != nil {
+			return 0, err
+		}
+		return b.parent.appendBytes(data)
+	}
+	if err = b.checkLimit("merge", b.length+int64(len(data))); err != nil {
+		return 0, err
+	}
+	if b.file != nil {
+		at = b.length
+		_, err = b.writeOffsetLocked(data, at)
+		return
+	}
+	buffer := b.Buffer()
+	if buffer == nil {
+		return 0, fmt.Errorf("buffer: merge: crunch buffer vanished")
+	}
+	at = b.length
+	if len(data) == 0 {
+		return
+	}
+	b.grow(buffer, at, int64(len(data)))
+	buffer.WriteBytes(at, data)
+	b.writes.Add(1)
+	return
+}
+
+// Concat builds a new buffer holding the contents of bufs in order
+func Concat(name string, bufs ...*Buffer) *Buffer {
+	total := int64(0)
+	for i := 0; i < len(bufs); i++ {
+		if bufs[i] == nil {
+			panic("CONCAT: buffer is nil")
+		}
+		total += bufs[i].Size()
+	}
+	data := make([]byte, 0, total)
+	for i := 0; i < len(bufs); i++ {
+		data = append(data, bufs[i].Bytes()...)
+	}
+	return NewBuffer(name, data)
+}
+
+// Split returns two independent buffers holding [0, at) and [at, length) of b,
+// leaving b unchanged
+func (b *Buffer) Split(at int64) (head, tail *Buffer, err error) {
+	if b == nil {
+		panic("SPLIT: buffer is nil")
+	}
+	b.Lock()
+	defer b.Unlock()
+	if b.Closed() {
+		return nil, nil, fmt.Errorf("buffer: split: %w", ErrClosed)
+	}
+	data := b.snapshot()
+	if at < 0 || at > int64(len(data)) {
+		return nil, nil, fmt.Errorf("buffer: split: offset %d out of range [0, %d]", at, len(data))
+	}
+	head = NewBuffer(b.name+".head", data[:at:at])
+	tail = NewBuffer(b.name+".tail", data[at:])
+	return
+}
+
+// Rest returns an independent buffer named after b holding the bytes from the
+// current offset to the end, leaving b unchanged
+func (b *Buffer) Rest() *Buffer {
+	if b == nil {
+		panic("REST: buffer is nil")
+	}
+	b.Lock()
+	defer b.Unlock()
+	data := b.snapshot()
+	if b.offset < int64(len(data)) {
+		data = data[b.offset:]
+	} else {
+		data = nil
+	}
+	return NewBuffer(b.name+".rest", data)
+}
+
+// Truncate shrinks the buffer to its first n bytes, pulling back the offset of
+// the buffer and of every reference sharing its bytes that lay past the new end
+func (b *Buffer) Truncate(n int64) error {
+	if b == nil {
+		panic("TRUNCATE: buffer is nil")
+	}
+	b.Lock()
+	defer b.Unlock()
+	if b.Closed() {
+		return fmt.Errorf("buffer: truncate: %w", ErrClosed)
+	}
+	if b.readOnly {
+		return fmt.Errorf("buffer: truncate: %w", ErrReadOnly)
+	}
+	b.unshare()
+	if b.parent != nil {
+		if err := b.windowResize("truncate"); err != nil {
+			return err
+		}
+		if err := b.parent.Truncate(n); err != nil {
+			return err
+		}
+		b.clampTruncated()
+		return nil
+	}
+	b.Buffer()
+	if n < 0 || n > b.length {
+		return fmt.Errorf("buffer: truncate: length %d out of range [0, %d]", n, b.length)
+	}
+	if b.file != nil {
+		if t, ok := b.file.(interface{ Truncate(int64) error }); ok {
+			if err := t.Truncate(n); err != nil {
+				return fmt.Errorf("buffer: truncate: %w", err)
+			}
+		}
+	}
+	b.length = n
+	if b.offset > n {
+		b.offset = n
+		b.bit = 0
+	}
+	b.truncations.Add(1)
+	return nil
+}
+
+// Reserve atomically extends the buffer by n zero bytes and returns the offset
+// they start at, so concurrent writers can each reserve a region and fill it
+// with WriteAt without serializing on a shared offset
+func (b *Buffer) Reserve(n int64) (offset int64, err error) {
+	if b == nil {
+		panic("RESERVE: buffer is nil")
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("buffer: reserve: negative length %d", n)
+	}
+	b.Lock()
+	defer b.Unlock()
+	if b.Closed() {
+		return 0, fmt.Errorf("buffer: reserve: %w", ErrClosed)
+	}
+	if b.readOnly {
+		return 0, fmt.Errorf("buffer: reserve: %w", ErrReadOnly)
+	}
+	b.unshare()
+	if b.parent != nil {
+		if err = b.windowResize("reserve"); err != nil {
+			return 0, err
+		}
+		return b.parent.Reserve(n)
+	}
+	offset = b.length
+	if err = b.checkLimit("reserve", offset+n); err != nil {
+		return 0, err
+	}
+	if b.file != nil {
+		_, err = b.writeFileAt(make([]byte, n), offset)
+		return
+	}
+	buffer := b.Buffer()
+	if buffer == nil {
+		return 0, fmt.Errorf("buffer: reserve: crunch buffer vanished")
+	}
+	b.grow(buffer, offset+n, 0)
+	b.writes.Add(1)
+	return
+}
+
+// Compact discards the bytes before the current offset, moving the unread
+// bytes to the start of the buffer and rewinding the offset to 0
+func (b *Buffer) Compact() error {
+	if b == nil {
+		panic("COMPACT: buffer is nil")
+	}
+	b.Lock()
+	defer b.Unlock()
+	if b.Closed() {
+		return fmt.Errorf("buffer: compact: %w", ErrClosed)
+	}
+	if b.readOnly {
+		return fmt.Errorf("buffer: compact: %w", ErrReadOnly)
+	}
+	b.unshare()
+	if b.parent != nil {
+		if b.readOnlyChain() {
+			return fmt.Errorf("buffer: compact: %w", ErrReadOnly)
+		}
+		return fmt.Errorf("buffer: compact: cannot compact the bytes shared by a reference")
+	}
+	buffer := b.Buffer()
+	if buffer == nil && b.file == nil {
+		return fmt.Errorf("buffer: compact: crunch buffer vanished")
+	}
+	consumed := min(max(b.offset, 0), b.length)
+	if consumed == 0 {
+		return nil
+	}
+	rest := make([]byte, b.length-consumed)
+	if b.file != nil {
+		if len(rest) > 0 {
+			if _, err := b.readFileAt(rest, consumed); err != nil {
+				return err
+			}
+			if _, err := b.writeFileAt(rest, 0); err != nil {
+				return err
+			}
+		}
+		if t, ok := b.file.(interface{ Truncate(int64) error }); ok {
+			if err := t.Truncate(int64(len(rest))); err != nil {
+				return fmt.Errorf("buffer: compact: %w", err)
+			}
+		}
+	} else if len(rest) > 0 {
+		copy(rest, buffer.ReadBytes(consumed, int64(len(rest))))
+		buffer.WriteBytes(0, rest)
+	}
+	b.length -= consumed
+	b.offset -= consumed
+	b.truncations.Add(1)
+	return nil
+}
@@ -0,0 +1,207 @@
+package crunchio
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+	"sync"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	b := NewBuffer("merge", []byte("ab"))
+	b.Seek(1, io.SeekStart)
+	if err := b.Merge(NewBuffer("c", []byte("cd")), NewBuffer("empty"), NewBuffer("e", []byte("e"))); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != "abcde" || b.Tell() != 1 {
+		t.Fatalf("Merge left %q at offset %d", got, b.Tell())
+	}
+	if err := b.Merge(); err != nil || b.String() != "abcde" {
+		t.Fatalf("Merge of nothing = %v, left %q", err, b.String())
+	}
+	if err := b.Merge(b); err != nil || b.String() != "abcdeabcde" {
+		t.Fatalf("Merge into itself = %v, left %q", err, b.String())
+	}
+	empty := NewBuffer("empty")
+	if err := empty.Merge(NewBuffer("empty"), NewBuffer("x", []byte("x"))); err != nil || empty.String() != "x" {
+		t.Fatalf("Merge into an empty buffer = %v, left %q", err, empty.String())
+	}
+	if err := b.Merge(nil); err == nil {
+		t.Fatal("Merge of a nil buffer succeeded")
+	}
+}
+
+func TestConcat(t *testing.T) {
+	b := Concat("concat", NewBuffer("a", []byte("a")), NewBuffer("empty"), NewBuffer("bc", []byte("bc")))
+	if got := b.String(); got != "abc" || b.Tell() != 0 {
+		t.Fatalf("Concat = %q at offset %d", got, b.Tell())
+	}
+}
+
+func TestSplit(t *testing.T) {
+	b := NewBuffer("split", []byte("hello world"))
+	for _, tt := range []struct {
+		at         int64
+		head, tail string
+	}{
+		{0, "", "hello world"},
+		{5, "hello", " world"},
+		{11, "hello world", ""},
+	} {
+		head, tail, err := b.Split(tt.at)
+		if err != nil || head.String() != tt.head || tail.String() != tt.tail {
+			t.Fatalf("Split(%d) = %q, %q, %v", tt.at, head.String(), tail.String(), err)
+		}
+		head.Write([]byte("X"))
+		tail.WriteAt([]byte("Y"), 0)
+	}
+	if got := b.String(); got != "hello world" {
+		t.Fatalf("writes to the halves changed the source to %q", got)
+	}
+	for _, at := range []int64{-1, 12} {
+		if _, _, err := b.Split(at); err == nil {
+			t.Fatalf("Split(%d) succeeded", at)
+		}
+	}
+}
+
+func TestReserveConcurrent(t *testing.T) {
+	const writers, records, size = 16, 200, 8
+	b := NewBuffer("reserve", []byte("head"))
+	ref := b.Reference()
+	offsets := make([][]int64, writers)
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			target := b
+			if w%2 == 1 {
+				target = ref
+			}
+			record := make([]byte, size)
+			for i := 0; i < records; i++ {
+				offset, err := target.Reserve(size)
+				if err != nil {
+					t.Errorf("Reserve = %v", err)
+					return
+				}
+				binary.LittleEndian.PutUint32(record, uint32(w))
+				binary.LittleEndian.PutUint32(record[4:], uint32(i))
+				if _, err := target.WriteAt(record, offset); err != nil {
+					t.Errorf("WriteAt = %v", err)
+					return
+				}
+				offsets[w] = append(offsets[w], offset)
+			}
+		}(w)
+	}
+	wg.Wait()
+	if b.Size() != 4+writers*records*size {
+		t.Fatalf("Size after reserving = %d, want %d", b.Size(), 4+writers*records*size)
+	}
+	seen := make(map[int64]bool)
+	data := b.Bytes()
+	for w := range offsets {
+		for i, offset := range offsets[w] {
+			if offset < 4 || (offset-4)%size != 0 || seen[offset] {
+				t.Fatalf("writer %d record %d reserved offset %d, overlapping another", w, i, offset)
+			}
+			seen[offset] = true
+			record := data[offset : offset+size]
+			if binary.LittleEndian.Uint32(record) != uint32(w) || binary.LittleEndian.Uint32(record[4:]) != uint32(i) {
+				t.Fatalf("record at %d holds % x, want writer %d record %d", offset, record, w, i)
+			}
+		}
+	}
+	if string(data[:4]) != "head" {
+		t.Fatalf("reservations overwrote the head, which reads %q", data[:4])
+	}
+	if _, err := b.Reserve(-1); err == nil {
+		t.Fatal("Reserve(-1) succeeded")
+	}
+}
+
+func TestCompact(t *testing.T) {
+	b := NewBuffer("compact")
+	b.Write([]byte("0123456789"))
+	b.Seek(0, io.SeekStart)
+	b.Read(make([]byte, 5))
+	if err := b.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != "56789" || b.Tell() != 0 || b.Size() != 5 {
+		t.Fatalf("Compact left %q at offset %d", got, b.Tell())
+	}
+	b.Seek(0, io.SeekEnd)
+	b.Write([]byte("ab"))
+	if got := b.String(); got != "56789ab" {
+		t.Fatalf("write after Compact left %q", got)
+	}
+	b.Seek(7, io.SeekStart)
+	if err := b.Compact(); err != nil || b.Size() != 0 || b.Tell() != 0 {
+		t.Fatalf("Compact of a fully read buffer = %v, Size %d, Tell %d", err, b.Size(), b.Tell())
+	}
+	if err := NewBuffer("compact", []byte("ab")).Reference().Compact(); err == nil {
+		t.Fatal("Compact through a reference succeeded")
+	}
+}
+
+func TestCompactClampsReferences(t *testing.T) {
+	b := NewBuffer("compact", []byte("0123456789"))
+	ref := b.Reference()
+	ref.Seek(9, io.SeekStart)
+	b.Seek(4, io.SeekStart)
+	if err := b.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	if ref.Tell() != 6 {
+		t.Fatalf("reference Tell after Compact = %d, want 6", ref.Tell())
+	}
+	if n, err := ref.Write([]byte("x")); n != 1 || err != nil {
+		t.Fatalf("reference Write = %d, %v", n, err)
+	}
+	if got := b.String(); got != "456789x" {
+		t.Fatalf("Compact and append left %q", got)
+	}
+}
+
+func TestCompactFile(t *testing.T) {
+	b, f := newTempFileBuffer(t, "consumed|unread")
+	b.Seek(9, io.SeekStart)
+	if err := b.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(f.Name())
+	if err != nil || string(data) != "unread" {
+		t.Fatalf("file holds %q, %v after Compact", data, err)
+	}
+}
+
+func TestRest(t *testing.T) {
+	b := NewBuffer("packet", []byte("\x00\x05hello world"))
+	b.SetByteOrder(binary.BigEndian)
+	if length, err := b.ReadU16(); length != 5 || err != nil {
+		t.Fatalf("ReadU16 = %d, %v", length, err)
+	}
+	rest := b.Rest()
+	if got := rest.String(); got != "hello world" || rest.Tell() != 0 {
+		t.Fatalf("Rest holds %q at offset %d", got, rest.Tell())
+	}
+	if rest.name != "packet.rest" {
+		t.Fatalf("Rest is named %q", rest.name)
+	}
+	if b.Tell() != 2 || b.String() != "\x00\x05hello world" {
+		t.Fatalf("Rest left the source holding %q at offset %d", b.String(), b.Tell())
+	}
+	rest.WriteAt([]byte("H"), 0)
+	b.WriteAt([]byte("W"), 8)
+	if rest.String() != "Hello world" || b.String() != "\x00\x05hello World" {
+		t.Fatalf("Rest shares bytes with its source: %q and %q", rest.String(), b.String())
+	}
+	b.Seek(20, io.SeekStart)
+	if rest := b.Rest(); rest.Size() != 0 {
+		t.Fatalf("Rest past the end holds %q", rest.String())
+	}
+}
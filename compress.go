@@ -0,0 +1,45 @@
+package crunchio
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// streamEOFReader reports io.EOF where a stream buffer would report an empty
+// read, so decompressors reading to the end of a buffer always terminate
+type streamEOFReader struct {
+	b *Buffer
+}
+
+func (r streamEOFReader) Read(dst []byte) (int, error) {
+	read, err := r.b.Read(dst)
+	if read == 0 && err == nil && len(dst) > 0 {
+		err = io.EOF
+	}
+	return read, err
+}
+
+// NewInflateReader returns a reader decompressing the buffer from its current
+// offset, detecting gzip by its magic bytes and falling back to raw DEFLATE
+func NewInflateReader(b *Buffer) (io.ReadCloser, error) {
+	if b == nil {
+		panic("NEWINFLATEREADER: buffer is nil")
+	}
+	var magic [2]byte
+	err := b.readExactAt(magic[:], b.Tell())
+	if errors.Is(err, ErrClosed) {
+		return nil, fmt.Errorf("buffer: inflate: %w", ErrClosed)
+	}
+	src := streamEOFReader{b}
+	if err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		zr, err := gzip.NewReader(src)
+		if err != nil {
+			return nil, fmt.Errorf("buffer: inflate: %w", err)
+		}
+		return zr, nil
+	}
+	return flate.NewReader(src), nil
+}
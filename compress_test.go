@@ -0,0 +1,50 @@
+package crunchio
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+)
+
+var compressText = strings.Repeat("crunch the bytes, ", 500)
+
+func TestInflateReaderGzip(t *testing.T) {
+	for _, stream := range []bool{false, true} {
+		b := NewBuffer("gzip")
+		zw := gzip.NewWriter(b)
+		zw.Write([]byte(compressText))
+		zw.Close()
+		b.Seek(0, io.SeekStart)
+		b.SetStream(stream)
+		r, err := NewInflateReader(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(r)
+		if err != nil || string(data) != compressText {
+			t.Fatalf("stream %v: read %d bytes, %v", stream, len(data), err)
+		}
+		if err := r.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestInflateReaderDeflate(t *testing.T) {
+	b := NewBuffer("deflate", []byte("header"))
+	b.Seek(0, io.SeekEnd)
+	zw, _ := flate.NewWriter(b, flate.BestSpeed)
+	zw.Write([]byte(compressText))
+	zw.Close()
+	b.Seek(6, io.SeekStart)
+	r, err := NewInflateReader(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil || string(data) != compressText {
+		t.Fatalf("read %d bytes, %v", len(data), err)
+	}
+}
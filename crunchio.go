@@ -1,33 +1,108 @@
+// Package crunchio provides Buffer, a seekable, lockable byte buffer built on
+// crunch that implements the standard io interfaces, along with typed readers
+// and writers for the values commonly found in binary formats.
 package crunchio
 
 import (
+	"encoding"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"hash"
 	"io"
+	"math"
+	"reflect"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	crunch "github.com/superwhiskers/crunch/v3"
 )
 
+// ErrClosed is returned by operations on a buffer that has been closed
+var ErrClosed = errors.New("buffer closed")
+
+// ErrReadOnly is returned by mutating operations on a read-only view or a
+// frozen buffer
+var ErrReadOnly = errors.New("buffer is read-only")
+
+// ErrNilAbstract is returned by WriteAbstract when given nil or a nil pointer
+var ErrNilAbstract = errors.New("abstract value is nil")
+
+// ErrTooLarge is returned by operations that would grow a buffer past the limit
+// set with SetLimit
+var ErrTooLarge = errors.New("buffer too large")
+
 // Bytes requires a type to be able to represent itself as a byte slice
 type Bytes interface {
 	Bytes() []byte
 }
 
+// Buffer is a seekable, lockable byte buffer backed by a crunch buffer, a file
+// or the bytes of a parent it references, the zero value is an empty buffer
+// ready to use
 type Buffer struct {
-	sync.Mutex
+	sync.RWMutex
 	name   string
 	stream bool
+	order  binary.ByteOrder
 	buffer *crunch.Buffer
 	parent *Buffer
 	length int64
 	offset int64
+	bit    int64
 	closed bool
+
+	runeEnd  int64
+	runeSize int64
+
+	windowed   bool
+	windowBase int64
+	windowLen  int64
+
+	capacity int64
+	limit    int64
+	readOnly bool
+	growth   Growth
+	file     io.ReadWriteSeeker
+
+	capacityWatcher func(oldCap, newCap int64)
+	watchedCapacity int64
+
+	trailerCRC32   bool
+	truncateArrays bool
+	strict         bool
+	noPlatformInts bool
+	cow            bool
+
+	readHook  func(offset int64, data []byte)
+	writeHook func(offset int64, data []byte)
+
+	hasher  hash.Hash
+	hashEnd int64
+
+	tracer  Tracer
+	onClose []func() error
+
+	snapshots    map[SnapshotID]*savepoint
+	snapshotSeq  SnapshotID
+	lastSnapshot SnapshotID
+
+	readDeadline  atomic.Int64
+	writeDeadline atomic.Int64
+
+	rootCache       atomic.Pointer[rootEntry]
+	truncations     atomic.Uint64
+	seenTruncations uint64
+	writes          atomic.Uint64
+	limiter         atomic.Pointer[rateLimiter]
 }
 
 func NewBuffer(name string, slices ...[]byte) *Buffer {
 	b := new(Buffer)
-	b.buffer = crunch.NewBuffer(slices...)
-	b.length = b.buffer.ByteCapacity()
+	b.setBacking(crunch.NewBuffer(slices...))
+	b.length = b.capacity
 	b.SetName(name)
 	return b
 }
@@ -60,40 +135,73 @@ func (b *Buffer) GetStream() bool {
 	return b.stream
 }
 
+// SetStrict controls whether seeking or reading past the logical end returns
+// io.ErrUnexpectedEOF instead of clamping the offset
+func (b *Buffer) SetStrict(strict bool) {
+	if b == nil {
+		panic("SETSTRICT: buffer is nil")
+	}
+	b.Lock()
+	defer b.Unlock()
+	b.strict = strict
+}
+
+// DisallowPlatformInts controls whether WriteAbstract rejects int and uint
+// with an error instead of writing them as 64 bits, forcing sized types
+func (b *Buffer) DisallowPlatformInts(disallow bool) {
+	if b == nil {
+		panic("DISALLOWPLATFORMINTS: buffer is nil")
+	}
+	b.Lock()
+	defer b.Unlock()
+	b.noPlatformInts = disallow
+}
+
 func (b *Buffer) Read(dst []byte) (read int, err error) {
 	if b == nil {
 		panic("READ: buffer is nil")
 	}
-	b.Lock()
+	if b.tracer != nil {
+		defer b.tracer.StartSpan("Read " + b.name)()
+	}
+	limiter, err := b.throttle(len(dst), b.readDeadline.Load())
+	if err != nil {
+		return 0, fmt.Errorf("buffer: read: %w", err)
+	}
+	at, read, err := b.read(dst)
+	limiter.refund(len(dst) - read)
+	b.afterRead(at, dst[:read])
+	return
+}
+
+func (b *Buffer) read(dst []byte) (at int64, read int, err error) {
+	if err = b.lockUntil(b.readDeadline.Load()); err != nil {
+		return 0, 0, fmt.Errorf("buffer: read: %w", err)
+	}
 	defer b.Unlock()
 	if b.Closed() {
-		return 0, io.EOF
+		return 0, 0, fmt.Errorf("buffer: read: %w", ErrClosed)
+	}
+	b.clampTruncated()
+	b.alignBit()
+	at = b.offset
+	if b.strict && len(dst) > 0 {
+		b.Buffer()
+		if b.offset > b.length {
+			return at, 0, fmt.Errorf("buffer: read: offset %d past end %d: %w", b.offset, b.length, io.ErrUnexpectedEOF)
+		}
 	}
 	if b.parent != nil {
-		read, err = b.parent.ReadOffset(dst, b.offset)
+		window, from := b.windowRead(dst, b.offset)
+		read, err = b.parent.ReadOffset(window, from)
 		b.offset += int64(read)
 		return
 	}
-	buffer := b.Buffer()
-	if buffer == nil {
-		return 0, fmt.Errorf("buffer: read: crunch buffer vanished")
-	}
 	if b.offset >= b.length {
 		b.offset = b.length
+		at = b.offset
 	}
-	toRead := b.length - b.offset
-	if int(toRead) > len(dst) {
-		toRead = int64(len(dst))
-	}
-	if toRead == 0 {
-		if b.GetStream() {
-			return 0, nil
-		} else {
-			return 0, io.EOF
-		}
-	}
-	bytes := buffer.ReadBytes(b.offset, toRead)
-	read = copy(dst, bytes)
+	read, err = b.readOffsetLocked(dst, b.offset)
 	b.offset += int64(read)
 	return
 }
@@ -102,12 +210,46 @@ func (b *Buffer) ReadOffset(dst []byte, offset int64) (read int, err error) {
 	if b == nil {
 		panic("READOFFSET: buffer is nil")
 	}
+	read, err = b.readOffset(dst, offset)
+	b.afterRead(offset, dst[:read])
+	return
+}
+
+// ReadAt reads into dst from offset without reading or moving the buffer's
+// offset, implementing io.ReaderAt by returning io.EOF with any short read
+func (b *Buffer) ReadAt(dst []byte, offset int64) (read int, err error) {
+	if b == nil {
+		panic("READAT: buffer is nil")
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("buffer: readat: negative offset %d", offset)
+	}
+	read, err = b.ReadOffset(dst, offset)
+	if err == nil && read < len(dst) {
+		err = io.EOF
+	}
+	return
+}
+
+func (b *Buffer) readOffset(dst []byte, offset int64) (read int, err error) {
 	b.Lock()
 	defer b.Unlock()
 	if b.Closed() {
-		return 0, io.EOF
+		return 0, fmt.Errorf("buffer: readoffset: %w", ErrClosed)
+	}
+	if b.parent != nil {
+		window, from := b.windowRead(dst, offset)
+		return b.parent.readOffset(window, from)
 	}
+	return b.readOffsetLocked(dst, offset)
+}
+
+// readOffsetLocked is readOffset for a caller already holding the lock
+func (b *Buffer) readOffsetLocked(dst []byte, offset int64) (read int, err error) {
 	buffer := b.Buffer()
+	if b.file != nil {
+		return b.readFileAt(dst, offset)
+	}
 	if buffer == nil {
 		return 0, fmt.Errorf("buffer: readoffset: crunch buffer vanished")
 	}
@@ -115,7 +257,7 @@ func (b *Buffer) ReadOffset(dst []byte, offset int64) (read int, err error) {
 	if int(toRead) > len(dst) {
 		toRead = int64(len(dst))
 	}
-	if toRead == 0 {
+	if toRead <= 0 {
 		if b.GetStream() {
 			return 0, nil
 		} else {
@@ -127,30 +269,132 @@ func (b *Buffer) ReadOffset(dst []byte, offset int64) (read int, err error) {
 	return
 }
 
+// readExact reads exactly len(dst) bytes at the current offset and advances
+// past them, leaving the offset untouched if fewer bytes are available
+func (b *Buffer) readExact(dst []byte) error {
+	at, err := b.readExactNext(dst)
+	if err == nil {
+		b.afterRead(at, dst)
+	}
+	return err
+}
+
+func (b *Buffer) readExactNext(dst []byte) (at int64, err error) {
+	b.Lock()
+	defer b.Unlock()
+	if b.Closed() {
+		return 0, fmt.Errorf("buffer: read: %w", ErrClosed)
+	}
+	b.clampTruncated()
+	b.alignBit()
+	at = b.offset
+	if b.parent != nil {
+		var from int64
+		if from, err = b.windowExact(len(dst), b.offset); err == nil {
+			err = b.parent.readExactAt(dst, from)
+		}
+	} else {
+		err = b.readExactAtLocked(dst, b.offset)
+	}
+	if err == nil {
+		b.offset += int64(len(dst))
+	}
+	return
+}
+
+// readExactAt reads exactly len(dst) bytes at offset
+func (b *Buffer) readExactAt(dst []byte, offset int64) error {
+	b.Lock()
+	defer b.Unlock()
+	if b.Closed() {
+		return fmt.Errorf("buffer: read: %w", ErrClosed)
+	}
+	return b.readExactAtHeld(dst, offset)
+}
+
+// readExactAtHeld is readExactAt for a caller already holding b's lock, going
+// through the window of a slice to its parent
+func (b *Buffer) readExactAtHeld(dst []byte, offset int64) error {
+	if b.parent != nil {
+		from, err := b.windowExact(len(dst), offset)
+		if err != nil {
+			return err
+		}
+		return b.parent.readExactAt(dst, from)
+	}
+	return b.readExactAtLocked(dst, offset)
+}
+
+// readExactAtLocked is readExactAt for a caller already holding the lock,
+// returning io.EOF if nothing is left and io.ErrUnexpectedEOF on a short read
+func (b *Buffer) readExactAtLocked(dst []byte, offset int64) error {
+	buffer := b.Buffer()
+	if buffer == nil && b.file == nil {
+		return fmt.Errorf("buffer: read: crunch buffer vanished")
+	}
+	if offset < 0 {
+		return fmt.Errorf("buffer: read: negative offset %d", offset)
+	}
+	if available := b.length - offset; available < int64(len(dst)) {
+		if available <= 0 {
+			return io.EOF
+		}
+		return io.ErrUnexpectedEOF
+	}
+	if len(dst) == 0 {
+		return nil
+	}
+	if b.file != nil {
+		_, err := b.readFileAt(dst, offset)
+		return err
+	}
+	copy(dst, buffer.ReadBytes(offset, int64(len(dst))))
+	return nil
+}
+
 func (b *Buffer) Write(src []byte) (wrote int, err error) {
 	if b == nil {
 		panic("WRITE: buffer is nil")
 	}
-	b.Lock()
+	if b.tracer != nil {
+		defer b.tracer.StartSpan("Write " + b.name)()
+	}
+	limiter, err := b.throttle(len(src), b.writeDeadline.Load())
+	if err != nil {
+		return 0, fmt.Errorf("buffer: write: %w", err)
+	}
+	at, wrote, err := b.write(src)
+	limiter.refund(len(src) - wrote)
+	b.afterWrite(at, src[:wrote])
+	return
+}
+
+func (b *Buffer) write(src []byte) (at int64, wrote int, err error) {
+	if err = b.lockUntil(b.writeDeadline.Load()); err != nil {
+		return 0, 0, fmt.Errorf("buffer: write: %w", err)
+	}
 	defer b.Unlock()
+	defer func() { b.hash(at, src[:wrote]) }()
 	if b.Closed() {
-		return 0, io.EOF
+		return 0, 0, fmt.Errorf("buffer: write: %w", ErrClosed)
+	}
+	if b.readOnly {
+		return 0, 0, fmt.Errorf("buffer: write: %w", ErrReadOnly)
 	}
+	b.clampTruncated()
+	b.alignBit()
+	at = b.offset
+	b.unshare()
 	if b.parent != nil {
-		wrote, err = b.parent.WriteOffset(src, b.offset)
+		var to int64
+		if to, err = b.windowWrite("write", len(src), b.offset); err != nil {
+			return
+		}
+		wrote, err = b.parent.WriteOffset(src, to)
 		b.offset += int64(wrote)
 		return
 	}
-	buffer := b.Buffer()
-	if buffer == nil {
-		return 0, fmt.Errorf("buffer: write: crunch buffer vanished")
-	}
-	if toGrow := (b.offset + int64(len(src))) - b.length; toGrow > 0 {
-		b.length += toGrow
-		buffer.Grow(toGrow)
-	}
-	buffer.WriteBytes(b.offset, src)
-	wrote = len(src)
+	wrote, err = b.writeOffsetLocked(src, b.offset)
 	b.offset += int64(wrote)
 	return
 }
@@ -159,116 +403,368 @@ func (b *Buffer) WriteOffset(src []byte, offset int64) (wrote int, err error) {
 	if b == nil {
 		panic("WRITEOFFSET: buffer is nil")
 	}
+	wrote, err = b.writeOffset(src, offset)
+	b.afterWrite(offset, src[:wrote])
+	return
+}
+
+func (b *Buffer) writeOffset(src []byte, offset int64) (wrote int, err error) {
 	b.Lock()
 	defer b.Unlock()
+	defer func() { b.hash(offset, src[:wrote]) }()
 	if b.Closed() {
-		return 0, io.EOF
+		return 0, fmt.Errorf("buffer: writeoffset: %w", ErrClosed)
+	}
+	if b.readOnly {
+		return 0, fmt.Errorf("buffer: writeoffset: %w", ErrReadOnly)
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("buffer: writeoffset: negative offset %d", offset)
+	}
+	b.unshare()
+	if b.parent != nil {
+		to, err := b.windowWrite("writeoffset", len(src), offset)
+		if err != nil {
+			return 0, err
+		}
+		return b.parent.WriteOffset(src, to)
+	}
+	return b.writeOffsetLocked(src, offset)
+}
+
+// writeOffsetLocked is writeOffset for a caller already holding the lock
+func (b *Buffer) writeOffsetLocked(src []byte, offset int64) (wrote int, err error) {
+	if offset < 0 {
+		return 0, fmt.Errorf("buffer: write: negative offset %d", offset)
+	}
+	if err = b.checkLimit("write", offset+int64(len(src))); err != nil {
+		return 0, err
 	}
 	buffer := b.Buffer()
-	if buffer == nil {
-		return 0, fmt.Errorf("buffer: writeoffset: crunch buffer vanished")
+	if b.file != nil {
+		return b.writeFileAt(src, offset)
 	}
-	if toGrow := (offset + int64(len(src))) - b.length; toGrow > 0 {
-		b.length += toGrow
-		buffer.Grow(toGrow)
+	if buffer == nil {
+		return 0, fmt.Errorf("buffer: write: crunch buffer vanished")
 	}
+	b.grow(buffer, offset, int64(len(src)))
 	buffer.WriteBytes(offset, src)
+	b.writes.Add(1)
 	wrote = len(src)
 	return
 }
 
+// WriteAt writes src at offset without reading or moving the buffer's offset,
+// implementing io.WriterAt
+func (b *Buffer) WriteAt(src []byte, offset int64) (wrote int, err error) {
+	if b == nil {
+		panic("WRITEAT: buffer is nil")
+	}
+	return b.WriteOffset(src, offset)
+}
+
+// grow extends the buffer to fit n bytes at offset, zeroing any gap left
+// between the old end and offset
+func (b *Buffer) grow(buffer *crunch.Buffer, offset, n int64) {
+	if offset+n <= b.length {
+		return
+	}
+	end := b.length
+	b.length = offset + n
+	if b.length > b.capacity {
+		next := b.growth.next(b.length)
+		if b.limit > 0 {
+			next = min(next, max(b.limit, b.length))
+		}
+		b.growBacking(buffer, next-b.capacity)
+	}
+	if gap := offset - end; gap > 0 {
+		buffer.WriteBytes(end, make([]byte, gap))
+	}
+}
+
+// byteOrder returns the byte order used for typed reads and writes
+func (b *Buffer) byteOrder() binary.ByteOrder {
+	if b.order == nil {
+		return binary.LittleEndian
+	}
+	return b.order
+}
+
+// SetByteOrder sets the byte order used by the typed reads and writes, such as
+// WriteAbstract, ReadAbstract, WriteValue and Marshal, a nil order restores
+// the default of little-endian
+//
+// Like SetName, it must not be called concurrently with other operations.
+func (b *Buffer) SetByteOrder(order binary.ByteOrder) {
+	if b == nil {
+		panic("SETBYTEORDER: buffer is nil")
+	}
+	b.order = order
+}
+
+// WithByteOrder sets the byte order like SetByteOrder and returns b, for use
+// when constructing a buffer
+func (b *Buffer) WithByteOrder(order binary.ByteOrder) *Buffer {
+	if b == nil {
+		panic("WITHBYTEORDER: buffer is nil")
+	}
+	b.SetByteOrder(order)
+	return b
+}
+
+// WriteAbstract encodes data at the current offset, int and uint are always
+// written as 64 bits regardless of platform, bool as a single 0 or 1 byte,
+// time.Time as int64 Unix nanoseconds, time.Duration as int64 nanoseconds,
+// complex numbers as their real then imaginary parts and any other
+// json.Marshaler or encoding.TextMarshaler as its marshaled bytes
+//
+// Unix nanoseconds only cover the years 1678 to 2262, a time.Time outside that
+// range, including the zero time, fails rather than wrapping around.
 func (b *Buffer) WriteAbstract(data any) (wrote int, err error) {
-	buffer := crunch.NewBuffer()
+	if b == nil {
+		panic("WRITEABSTRACT: buffer is nil")
+	}
+	if b.tracer != nil {
+		defer b.tracer.StartSpan("WriteAbstract " + b.name)()
+	}
+	var scratch [16]byte
+	encoded, err := b.encodeAbstract(&scratch, data)
+	if err != nil {
+		return 0, err
+	}
+	return b.Write(encoded)
+}
+
+// WriteAbstractAt encodes data like WriteAbstract but writes it at offset,
+// leaving the buffer's offset unchanged
+func (b *Buffer) WriteAbstractAt(offset int64, data any) (wrote int, err error) {
+	if b == nil {
+		panic("WRITEABSTRACTAT: buffer is nil")
+	}
+	if b.tracer != nil {
+		defer b.tracer.StartSpan("WriteAbstractAt " + b.name)()
+	}
+	var scratch [16]byte
+	encoded, err := b.encodeAbstract(&scratch, data)
+	if err != nil {
+		return 0, err
+	}
+	return b.WriteAt(encoded, offset)
+}
 
-	switch data.(type) {
+// WriteAbstractAll encodes each value like WriteAbstract and writes them all
+// with a single Write, on the first value that fails to encode it writes the
+// values before it and returns their length along with the error
+func (b *Buffer) WriteAbstractAll(values ...any) (wrote int, err error) {
+	if b == nil {
+		panic("WRITEABSTRACTALL: buffer is nil")
+	}
+	if b.tracer != nil {
+		defer b.tracer.StartSpan("WriteAbstractAll " + b.name)()
+	}
+	var scratch [16]byte
+	var record []byte
+	for i := range values {
+		encoded, encodeErr := b.encodeAbstract(&scratch, values[i])
+		if encodeErr != nil {
+			err = fmt.Errorf("buffer: writeabstractall: value %d: %w", i, encodeErr)
+			break
+		}
+		record = append(record, encoded...)
+	}
+	if len(record) == 0 {
+		return 0, err
+	}
+	wrote, writeErr := b.Write(record)
+	if writeErr != nil {
+		return wrote, writeErr
+	}
+	return wrote, err
+}
+
+// encodeAbstract encodes data for the WriteAbstract family, fixed-size scalars
+// are encoded straight into scratch, byte slices and strings are returned as
+// they are and only readers and Bytes go through a temporary crunch buffer
+func (b *Buffer) encodeAbstract(scratch *[16]byte, data any) ([]byte, error) {
+	if isNil(data) {
+		return nil, fmt.Errorf("buffer: writeabstract: %w", ErrNilAbstract)
+	}
+	if n, ok, err := b.putAbstractScalar(scratch[:], data); ok {
+		return scratch[:n], err
+	}
+	switch data := data.(type) {
 	case io.Reader:
-		bytes, readErr := io.ReadAll(data.(io.Reader))
-		if readErr != nil {
-			err = readErr
-			return
+		r := io.Reader(data)
+		limit := b.sizeLimit()
+		if limit > 0 {
+			r = io.LimitReader(r, limit+1)
 		}
+		bytes, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		if limit > 0 && int64(len(bytes)) > limit {
+			return nil, fmt.Errorf("buffer: writeabstract: reader exceeds limit %d: %w", limit, ErrTooLarge)
+		}
+		buffer := crunch.NewBuffer()
 		buffer.Grow(int64(len(bytes)))
 		buffer.WriteBytes(0, bytes)
+		return buffer.Bytes(), nil
 	case Bytes:
-		bytes := data.(Bytes).Bytes()
-		buffer.Grow(int64(len(bytes)))
-		buffer.WriteBytes(0, bytes)
-	case byte, bool, int, uint:
-		buffer.Grow(1)
-		buffer.WriteByte(0, data.(byte))
-	case []byte, string:
-		bytes := data.([]byte)
+		bytes := data.Bytes()
+		buffer := crunch.NewBuffer()
 		buffer.Grow(int64(len(bytes)))
 		buffer.WriteBytes(0, bytes)
+		return buffer.Bytes(), nil
+	case []byte:
+		return data, nil
+	case string:
+		return []byte(data), nil
 	case []string:
-		strings := data.([]string)
-		for i := 0; i < len(strings); i++ {
-			buffer.Grow(int64(len(strings[i])))
-			buffer.WriteBytesNext([]byte(strings[i]))
+		size := 0
+		for i := 0; i < len(data); i++ {
+			size += len(data[i])
 		}
-	case int16:
-		buffer.Grow(2)
-		buffer.WriteI16LE(0, []int16{data.(int16)})
+		encoded := make([]byte, 0, size)
+		for i := 0; i < len(data); i++ {
+			encoded = append(encoded, data[i]...)
+		}
+		return encoded, nil
 	case []int16:
-		numbers := data.([]int16)
-		buffer.Grow(int64(2 * len(numbers)))
-		buffer.WriteI16LE(0, numbers)
-	case int32:
-		buffer.Grow(4)
-		buffer.WriteI32LE(0, []int32{data.(int32)})
+		return encodeValues(data, b.byteOrder()), nil
 	case []int32:
-		numbers := data.([]int32)
-		buffer.Grow(int64(4 * len(numbers)))
-		buffer.WriteI32LE(0, numbers)
-	case int64:
-		buffer.Grow(8)
-		buffer.WriteI64LE(0, []int64{data.(int64)})
+		return encodeValues(data, b.byteOrder()), nil
 	case []int64:
-		numbers := data.([]int64)
-		buffer.Grow(int64(8 * len(numbers)))
-		buffer.WriteI64LE(0, numbers)
-	case uint16:
-		buffer.Grow(2)
-		buffer.WriteU16LE(0, []uint16{data.(uint16)})
+		return encodeValues(data, b.byteOrder()), nil
 	case []uint16:
-		numbers := data.([]uint16)
-		buffer.Grow(int64(2 * len(numbers)))
-		buffer.WriteU16LE(0, numbers)
-	case uint32:
-		buffer.Grow(4)
-		buffer.WriteU32LE(0, []uint32{data.(uint32)})
+		return encodeValues(data, b.byteOrder()), nil
 	case []uint32:
-		numbers := data.([]uint32)
-		buffer.Grow(int64(4 * len(numbers)))
-		buffer.WriteU32LE(0, numbers)
-	case uint64:
-		buffer.Grow(8)
-		buffer.WriteU64LE(0, []uint64{data.(uint64)})
+		return encodeValues(data, b.byteOrder()), nil
 	case []uint64:
-		numbers := data.([]uint64)
-		buffer.Grow(int64(8 * len(numbers)))
-		buffer.WriteU64LE(0, numbers)
-	case float32:
-		buffer.Grow(4)
-		buffer.WriteF32LE(0, []float32{data.(float32)})
+		return encodeValues(data, b.byteOrder()), nil
 	case []float32:
-		numbers := data.([]float32)
-		buffer.Grow(int64(4 * len(numbers)))
-		buffer.WriteF32LE(0, numbers)
-	case float64:
-		buffer.Grow(8)
-		buffer.WriteF64LE(0, []float64{data.(float64)})
+		return encodeValues(data, b.byteOrder()), nil
 	case []float64:
-		numbers := data.([]float64)
-		buffer.Grow(int64(8 * len(numbers)))
-		buffer.WriteF64LE(0, numbers)
-	default:
-		err = fmt.Errorf("buffer: Unsupported type for abstract write: %v", data)
-		return
+		return encodeValues(data, b.byteOrder()), nil
+	case []complex64:
+		encoded := make([]byte, 8*len(data))
+		for i, number := range data {
+			putComplex64(encoded[8*i:], b.byteOrder(), number)
+		}
+		return encoded, nil
+	case []complex128:
+		encoded := make([]byte, 16*len(data))
+		for i, number := range data {
+			putComplex128(encoded[16*i:], b.byteOrder(), number)
+		}
+		return encoded, nil
+	case json.Marshaler:
+		bytes, err := data.MarshalJSON()
+		if err != nil {
+			return nil, fmt.Errorf("buffer: writeabstract: %w", err)
+		}
+		return bytes, nil
+	case encoding.TextMarshaler:
+		bytes, err := data.MarshalText()
+		if err != nil {
+			return nil, fmt.Errorf("buffer: writeabstract: %w", err)
+		}
+		return bytes, nil
 	}
+	return nil, fmt.Errorf("buffer: Unsupported type for abstract write: %v", data)
+}
 
-	wrote, err = b.Write(buffer.Bytes())
-	return
+// minUnixNano and maxUnixNano bound the times whose Unix nanoseconds fit in an
+// int64
+var (
+	minUnixNano = time.Unix(0, math.MinInt64)
+	maxUnixNano = time.Unix(0, math.MaxInt64)
+)
+
+// putAbstractScalar encodes data into dst if it is a fixed-size scalar,
+// returning how many bytes it took and false for any other type
+func (b *Buffer) putAbstractScalar(dst []byte, data any) (n int, ok bool, err error) {
+	order := b.byteOrder()
+	switch data := data.(type) {
+	case byte:
+		dst[0] = data
+		return 1, true, nil
+	case int8:
+		dst[0] = byte(data)
+		return 1, true, nil
+	case bool:
+		dst[0] = 0
+		if data {
+			dst[0] = 1
+		}
+		return 1, true, nil
+	case int:
+		if b.noPlatformInts {
+			return 0, true, fmt.Errorf("buffer: writeabstract: %T is platform-sized, use a sized integer type", data)
+		}
+		order.PutUint64(dst, uint64(data))
+		return 8, true, nil
+	case uint:
+		if b.noPlatformInts {
+			return 0, true, fmt.Errorf("buffer: writeabstract: %T is platform-sized, use a sized integer type", data)
+		}
+		order.PutUint64(dst, uint64(data))
+		return 8, true, nil
+	case int16:
+		order.PutUint16(dst, uint16(data))
+		return 2, true, nil
+	case uint16:
+		order.PutUint16(dst, data)
+		return 2, true, nil
+	case int32:
+		order.PutUint32(dst, uint32(data))
+		return 4, true, nil
+	case uint32:
+		order.PutUint32(dst, data)
+		return 4, true, nil
+	case int64:
+		order.PutUint64(dst, uint64(data))
+		return 8, true, nil
+	case uint64:
+		order.PutUint64(dst, data)
+		return 8, true, nil
+	case float32:
+		order.PutUint32(dst, math.Float32bits(data))
+		return 4, true, nil
+	case float64:
+		order.PutUint64(dst, math.Float64bits(data))
+		return 8, true, nil
+	case complex64:
+		putComplex64(dst, order, data)
+		return 8, true, nil
+	case complex128:
+		putComplex128(dst, order, data)
+		return 16, true, nil
+	case time.Time:
+		if data.Before(minUnixNano) || data.After(maxUnixNano) {
+			return 0, true, fmt.Errorf("buffer: writeabstract: %v is outside the range of Unix nanoseconds", data)
+		}
+		order.PutUint64(dst, uint64(data.UnixNano()))
+		return 8, true, nil
+	case time.Duration:
+		order.PutUint64(dst, uint64(data))
+		return 8, true, nil
+	}
+	return 0, false, nil
+}
+
+// isNil reports whether data is nil or a typed nil pointer, map, channel or
+// function, nil slices are left alone as they encode as empty
+func isNil(data any) bool {
+	if data == nil {
+		return true
+	}
+	switch v := reflect.ValueOf(data); v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Chan, reflect.Func:
+		return v.IsNil()
+	}
+	return false
 }
 
 func (b *Buffer) Seek(to int64, whence int) (offset int64, err error) {
@@ -278,57 +774,214 @@ func (b *Buffer) Seek(to int64, whence int) (offset int64, err error) {
 	b.Lock()
 	defer b.Unlock()
 	if b.Closed() {
-		return 0, io.EOF
+		return 0, fmt.Errorf("buffer: seek: %w", ErrClosed)
 	}
 	buffer := b.Buffer()
-	if buffer == nil {
+	if buffer == nil && b.root().file == nil {
 		return 0, fmt.Errorf("buffer: seek: crunch buffer vanished")
 	}
+	if b.parent != nil {
+		b.clampTruncated()
+		b.length = b.rootLength()
+	}
+	offset = b.offset
 	switch whence {
 	case io.SeekStart:
-		b.offset = to
+		offset = to
 	case io.SeekCurrent:
-		b.offset += to
+		offset += to
 	case io.SeekEnd:
-		b.offset = b.length - to
+		offset = b.length - to
 	}
-	offset = b.offset
-	if b.parent == nil {
+	if b.strict && offset > b.length {
+		return b.offset, fmt.Errorf("buffer: seek: offset %d past end %d: %w", offset, b.length, io.ErrUnexpectedEOF)
+	}
+	b.offset = offset
+	b.bit = 0
+	if b.parent == nil && buffer != nil {
 		buffer.SeekByte(offset, false)
 	}
 	return
 }
 
+// rootLength returns the current length of the bytes a reference can see in
+// the buffer that owns them, read under its lock so growth by other writers is
+// seen
+func (b *Buffer) rootLength() int64 {
+	root, base, end := b.span()
+	root.RLock()
+	defer root.RUnlock()
+	return visibleLength(root.length, base, end)
+}
+
+// visibleLength clamps the length of a root to the span [base, end) of it
+// that a reference can see
+func visibleLength(length, base, end int64) int64 {
+	return max(min(length, end)-base, 0)
+}
+
+// clampTruncated pulls the offset of a reference back to the end of the bytes
+// it shares if they were truncated since it last looked, the caller must hold
+// b's lock
+func (b *Buffer) clampTruncated() {
+	if b.parent == nil {
+		return
+	}
+	if seen := b.root().truncations.Load(); seen != b.seenTruncations {
+		b.seenTruncations = seen
+		if length := b.rootLength(); b.offset > length {
+			b.offset = length
+			b.bit = 0
+		}
+	}
+}
+
+func (b *Buffer) Tell() int64 {
+	if b == nil {
+		panic("TELL: buffer is nil")
+	}
+	b.Lock()
+	defer b.Unlock()
+	b.clampTruncated()
+	return b.offset
+}
+
+func (b *Buffer) Remaining() int64 {
+	if b == nil {
+		panic("REMAINING: buffer is nil")
+	}
+	b.Lock()
+	defer b.Unlock()
+	b.clampTruncated()
+	if remaining := b.lengthLocked() - b.offset; remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
 func (b *Buffer) Close() error {
 	if b == nil {
 		panic("CLOSE: buffer is nil")
 	}
 	b.Lock()
-	defer b.Unlock()
 	b.closed = true
-	return nil
+	var err error
+	if b.file != nil {
+		err = b.syncLocked()
+	}
+	if b.trailerCRC32 {
+		err = errors.Join(err, b.verifyTrailerCRC32())
+	}
+	onClose := b.onClose
+	b.onClose = nil
+	b.Unlock()
+	for i := len(onClose) - 1; i >= 0; i-- {
+		if closeErr := onClose[i](); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// OnClose registers fn to run when the buffer is closed, after the lock is
+// released, callbacks run in the reverse order they were registered and all of
+// them run even if one fails, Close returning the first error
+func (b *Buffer) OnClose(fn func() error) {
+	if b == nil {
+		panic("ONCLOSE: buffer is nil")
+	}
+	b.Lock()
+	defer b.Unlock()
+	b.onClose = append(b.onClose, fn)
 }
 
 func (b *Buffer) Closed() bool {
 	if b == nil {
 		panic("CLOSED: buffer is nil")
 	}
-	if b.parent != nil {
-		return b.parent.Closed()
-	}
-	return b.closed
+	return b.root().closed
 }
 
+// Buffer returns the crunch buffer holding the bytes, installing an empty one
+// on first use so that a zero Buffer is ready to use
 func (b *Buffer) Buffer() *crunch.Buffer {
 	if b == nil {
 		panic("BUFFER: buffer is nil")
 	}
-	buffer := b.buffer
-	if b.parent != nil {
-		buffer = b.parent.Buffer()
+	root, base, end := b.span()
+	if root != b {
+		root.RLock()
+		defer root.RUnlock()
+		b.length = visibleLength(root.length, base, end)
+		return root.buffer
 	}
-	b.length = buffer.ByteCapacity()
-	return buffer
+	if b.buffer == nil && b.file == nil {
+		b.setBacking(crunch.NewBuffer())
+	}
+	return b.buffer
+}
+
+// maxReferenceDepth bounds how many parents root will walk through before
+// assuming the reference chain is cyclic
+const maxReferenceDepth = 1 << 20
+
+// rootEpoch counts detaches, any of which may change the root of a reference
+// further down the chain and so invalidates every cached root
+var rootEpoch atomic.Uint64
+
+// rootEntry is a cached root along with the epoch it was resolved in and the
+// span [base, end) of the root's bytes visible through any slices on the way
+type rootEntry struct {
+	root  *Buffer
+	epoch uint64
+	base  int64
+	end   int64
+}
+
+// root walks the reference chain up to the buffer that owns the bytes, caching
+// the result on b so later calls skip the walk
+func (b *Buffer) root() *Buffer {
+	if b.parent == nil {
+		return b
+	}
+	return b.resolve().root
+}
+
+// span returns the root of b along with the span [base, end) of its bytes that
+// b can see, which is all of them unless b is or references a slice
+func (b *Buffer) span() (root *Buffer, base, end int64) {
+	if b.parent == nil {
+		return b, 0, math.MaxInt64
+	}
+	entry := b.resolve()
+	return entry.root, entry.base, entry.end
+}
+
+// resolve walks the reference chain of b, returning its cached entry if the
+// chain hasn't been detached from since
+func (b *Buffer) resolve() *rootEntry {
+	epoch := rootEpoch.Load()
+	if cached := b.rootCache.Load(); cached != nil && cached.epoch == epoch {
+		return cached
+	}
+	var windows []*Buffer
+	root := b
+	for depth := 0; root.parent != nil; depth++ {
+		if depth >= maxReferenceDepth {
+			panic("ROOT: reference chain is cyclic or too deep")
+		}
+		if root.windowed {
+			windows = append(windows, root)
+		}
+		root = root.parent
+	}
+	entry := &rootEntry{root: root, epoch: epoch, end: math.MaxInt64}
+	for i := len(windows) - 1; i >= 0; i-- {
+		entry.base += windows[i].windowBase
+		entry.end = min(entry.end, entry.base+windows[i].windowLen)
+	}
+	b.rootCache.Store(entry)
+	return entry
 }
 
 func (b *Buffer) Reference() *Buffer {
@@ -338,52 +991,291 @@ func (b *Buffer) Reference() *Buffer {
 	nb := new(Buffer)
 	nb.name = b.name
 	nb.stream = b.stream
+	nb.order = b.order
+	nb.readOnly = b.readOnly
+	nb.strict = b.strict
 	nb.parent = b
 	return nb
 }
 
+// readOnlyChain reports whether b or any buffer along its reference chain is
+// read-only, which a plain reference finds out when its writes reach that
+// buffer but a copy-on-write one, writing to a private copy, has to be told
+func (b *Buffer) readOnlyChain() bool {
+	for depth := 0; b != nil; depth++ {
+		if depth > maxReferenceDepth {
+			panic("ROOT: reference chain is cyclic or too deep")
+		}
+		if b.readOnly {
+			return true
+		}
+		b = b.parent
+	}
+	return false
+}
+
+// ReadOnly returns a reference to b whose reads and seeks work as usual but
+// whose mutating operations return ErrReadOnly, as do those of any reference
+// taken from it
+func (b *Buffer) ReadOnly() *Buffer {
+	if b == nil {
+		panic("READONLY: buffer is nil")
+	}
+	nb := b.Reference()
+	nb.readOnly = true
+	return nb
+}
+
+// ReferenceCOW returns a reference to b that reads the bytes it shares with b
+// until its first mutating operation, which first detaches it onto a private
+// copy so the change is never seen by b
+func (b *Buffer) ReferenceCOW() *Buffer {
+	if b == nil {
+		panic("REFERENCECOW: buffer is nil")
+	}
+	nb := b.Reference()
+	nb.readOnly = b.readOnlyChain()
+	nb.cow = true
+	return nb
+}
+
+// unshare detaches a copy-on-write reference ahead of a mutation, the caller
+// must hold the lock
+func (b *Buffer) unshare() {
+	if b.cow {
+		b.detachLocked()
+		b.cow = false
+	}
+}
+
+// ReferenceRO is an alias of ReadOnly
+func (b *Buffer) ReferenceRO() *Buffer {
+	if b == nil {
+		panic("REFERENCERO: buffer is nil")
+	}
+	return b.ReadOnly()
+}
+
+// Freeze makes b itself read-only for good, its mutating operations and those
+// of every reference to it returning ErrReadOnly from then on
+func (b *Buffer) Freeze() {
+	if b == nil {
+		panic("FREEZE: buffer is nil")
+	}
+	b.Lock()
+	defer b.Unlock()
+	b.readOnly = true
+}
+
+// Detach severs a reference from its parent, giving it a private snapshot of
+// the bytes it could see
+func (b *Buffer) Detach() {
+	if b == nil {
+		panic("DETACH: buffer is nil")
+	}
+	b.Lock()
+	defer b.Unlock()
+	b.detachLocked()
+}
+
+// detachLocked is Detach for a caller already holding the lock
+func (b *Buffer) detachLocked() {
+	if b.parent == nil {
+		return
+	}
+	data := b.snapshot()
+	b.setBacking(crunch.NewBuffer(data))
+	b.length = int64(len(data))
+	b.parent = nil
+	b.windowed = false
+	b.rootCache.Store(nil)
+	rootEpoch.Add(1)
+}
+
+// snapshot returns a copy of the bytes visible to b, reading through the
+// parent chain for a reference, the caller must hold b's lock
+func (b *Buffer) snapshot() []byte {
+	root, base, end := b.span()
+	if root != b {
+		root.Lock()
+		defer root.Unlock()
+	}
+	data := make([]byte, visibleLength(root.length, base, end))
+	if root.file != nil {
+		if _, err := root.readFileAt(data, base); err != nil && err != io.EOF {
+			return nil
+		}
+	} else if root.buffer != nil && len(data) > 0 {
+		copy(data, root.buffer.Bytes()[base:])
+	}
+	return data
+}
+
+// Clone returns a fully independent buffer holding the bytes visible to b,
+// with the same name, offset and settings, even when b is a reference
+func (b *Buffer) Clone() *Buffer {
+	if b == nil {
+		panic("CLONE: buffer is nil")
+	}
+	b.Lock()
+	defer b.Unlock()
+	nb := NewBuffer(b.name, b.snapshot())
+	root := b.root()
+	nb.stream = b.stream
+	nb.order = b.order
+	nb.offset = b.offset
+	nb.bit = b.bit
+	nb.growth = root.growth
+	nb.truncateArrays = b.truncateArrays
+	nb.noPlatformInts = b.noPlatformInts
+	return nb
+}
+
 func (b *Buffer) Copy() *Buffer {
 	if b == nil {
 		panic("COPY: buffer is nil")
 	}
 	b.Lock()
 	defer b.Unlock()
+	data := b.snapshot()
 	nb := new(Buffer)
-	nb.buffer = crunch.NewBuffer(b.buffer.Bytes())
-	nb.length = b.length
+	nb.setBacking(crunch.NewBuffer(data))
+	nb.length = int64(len(data))
 	return nb
 }
 
+// CopyInto replaces the contents of dst with those of b and rewinds it, like
+// Copy but reusing the capacity dst already has, dst must own its bytes
+func (b *Buffer) CopyInto(dst *Buffer) error {
+	if b == nil {
+		panic("COPYINTO: buffer is nil")
+	}
+	if dst == nil {
+		panic("COPYINTO: destination is nil")
+	}
+	b.Lock()
+	data := b.snapshot()
+	b.Unlock()
+
+	dst.Lock()
+	defer dst.Unlock()
+	if dst.Closed() {
+		return fmt.Errorf("buffer: copyinto: %w", ErrClosed)
+	}
+	if dst.readOnly {
+		return fmt.Errorf("buffer: copyinto: %w", ErrReadOnly)
+	}
+	if dst.parent != nil {
+		return fmt.Errorf("buffer: copyinto: cannot replace the bytes shared by a reference")
+	}
+	if err := dst.setContents(data); err != nil {
+		return fmt.Errorf("buffer: copyinto: %w", err)
+	}
+	dst.offset = 0
+	dst.bit = 0
+	return nil
+}
+
+// Reset empties the buffer, references and read-only buffers are only rewound
+// and leave their bytes untouched (see ResetParent)
 func (b *Buffer) Reset() {
 	if b == nil {
 		panic("RESET: buffer is nil")
 	}
 	b.Lock()
 	defer b.Unlock()
-	b.length = 0
 	b.offset = 0
-	if b.parent != nil {
-		b.parent.Reset()
+	if b.parent != nil || b.readOnly {
 		return
 	}
-	b.buffer.Reset()
+	b.length = 0
+	b.writes.Add(1)
+	if b.buffer != nil {
+		b.buffer.Reset()
+		b.capacity = b.buffer.ByteCapacity()
+	}
+}
+
+// ResetParent resets the buffer that owns a reference's bytes, discarding them
+// for the parent and every reference sharing them
+//
+// A copy-on-write reference on the way to the owner is detached first and
+// reset on its own, leaving the bytes it was sharing untouched.
+func (b *Buffer) ResetParent() error {
+	if b == nil {
+		panic("RESETPARENT: buffer is nil")
+	}
+	if err := b.resetOwner(); err != nil {
+		return err
+	}
+	b.Lock()
+	b.offset = 0
+	b.bit = 0
+	b.Unlock()
+	return nil
+}
+
+// resetOwner walks up the reference chain of b and resets the buffer that owns
+// its bytes, holding one lock at a time
+func (b *Buffer) resetOwner() error {
+	b.Lock()
+	if b.readOnly {
+		b.Unlock()
+		return fmt.Errorf("buffer: resetparent: %w", ErrReadOnly)
+	}
+	b.unshare()
+	if err := b.windowResize("resetparent"); err != nil {
+		b.Unlock()
+		return err
+	}
+	parent := b.parent
+	b.Unlock()
+	if parent != nil {
+		return parent.resetOwner()
+	}
+	b.Reset()
+	return nil
 }
 
 func (b *Buffer) ByteCapacity() int64 {
 	if b == nil {
 		panic("BYTECAPACITY: buffer is nil")
 	}
-	if buffer := b.Buffer(); buffer != nil {
+	return b.Size()
+}
+
+// Len returns the number of unread bytes past the offset, like the Len of a
+// bytes.Reader, it is an int rather than the int64 used for lengths elsewhere
+// and the total length is reported by Size
+//
+// Like Size it only takes read locks and modifies no state, so it is safe to
+// call while other goroutines write.
+func (b *Buffer) Len() int {
+	if b == nil {
+		panic("LEN: buffer is nil")
+	}
+	b.RLock()
+	offset := b.offset
+	b.RUnlock()
+	return int(max(b.rootLength()-offset, 0))
+}
+
+// lengthLocked returns the logical length for a caller holding b's lock
+func (b *Buffer) lengthLocked() int64 {
+	if b.parent == nil {
 		return b.length
 	}
-	return 0
+	return b.rootLength()
 }
 
-func (b *Buffer) Size() int {
+// Size returns the logical length of the buffer regardless of the offset, like
+// the Size of a bytes.Reader, under a read lock and without modifying any
+// state, so it is safe to call while other goroutines write
+func (b *Buffer) Size() int64 {
 	if b == nil {
 		panic("SIZE: buffer is nil")
 	}
-	return int(b.ByteCapacity())
+	return b.rootLength()
 }
 
 func (b *Buffer) Bytes() []byte {
@@ -392,7 +1284,27 @@ func (b *Buffer) Bytes() []byte {
 	}
 	b.Lock()
 	defer b.Unlock()
-	return b.Buffer().Bytes()
+	root, base, end := b.span()
+	if root != b {
+		root.Lock()
+		defer root.Unlock()
+	}
+	buffer := root.Buffer()
+	length := visibleLength(root.length, base, end)
+	if root.file != nil {
+		data := make([]byte, length)
+		if _, err := root.readFileAt(data, base); err != nil && err != io.EOF {
+			return nil
+		}
+		return data
+	}
+	if buffer == nil {
+		return nil
+	}
+	if length == 0 {
+		return buffer.Bytes()[:0]
+	}
+	return buffer.Bytes()[base : base+length]
 }
 
 func (b *Buffer) String() string {
@@ -0,0 +1,902 @@
+package crunchio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestErrClosed(t *testing.T) {
+	b := NewBuffer("closed", []byte("ab"))
+	p := make([]byte, 4)
+	b.Read(p)
+	if _, err := b.Read(p); !errors.Is(err, io.EOF) || errors.Is(err, ErrClosed) {
+		t.Fatalf("Read at the end = %v, want io.EOF", err)
+	}
+	ref := b.Reference()
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !b.Closed() || !ref.Closed() {
+		t.Fatal("Closed reports an open buffer after Close")
+	}
+	if _, err := b.Read(p); !errors.Is(err, ErrClosed) || errors.Is(err, io.EOF) {
+		t.Fatalf("Read after Close = %v, want ErrClosed", err)
+	}
+	if _, err := b.Write(p); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Write after Close = %v, want ErrClosed", err)
+	}
+	if _, err := b.Seek(0, io.SeekStart); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Seek after Close = %v, want ErrClosed", err)
+	}
+	if _, err := ref.ReadAt(p, 0); !errors.Is(err, ErrClosed) {
+		t.Fatalf("ReadAt through a reference after Close = %v, want ErrClosed", err)
+	}
+}
+
+func TestOnClose(t *testing.T) {
+	b := NewBuffer("closed")
+	first, second := errors.New("first"), errors.New("second")
+	var order []string
+	for _, cb := range []struct {
+		name string
+		err  error
+	}{{"a", nil}, {"b", second}, {"c", nil}, {"d", first}, {"e", nil}} {
+		b.OnClose(func() error {
+			if !b.Closed() {
+				t.Errorf("callback %s ran before the buffer was closed", cb.name)
+			}
+			order = append(order, cb.name)
+			return cb.err
+		})
+	}
+	if err := b.Close(); err != first {
+		t.Fatalf("Close = %v, want the first error returned", err)
+	}
+	if want := []string{"e", "d", "c", "b", "a"}; !slices.Equal(order, want) {
+		t.Fatalf("callbacks ran in order %q, want %q", order, want)
+	}
+	if err := b.Close(); err != nil || len(order) != 5 {
+		t.Fatalf("second Close = %v and ran %q", err, order[5:])
+	}
+}
+
+func TestWriteZeroFillsGap(t *testing.T) {
+	b := NewBuffer("gap")
+	b.Seek(100, io.SeekStart)
+	if n, err := b.Write([]byte{1, 2}); n != 2 || err != nil {
+		t.Fatalf("Write = %d, %v", n, err)
+	}
+	data := b.Bytes()
+	if len(data) != 102 || !slices.Equal(data[:100], make([]byte, 100)) || data[100] != 1 || data[101] != 2 {
+		t.Fatalf("Write past the end left % x", data)
+	}
+	b.WriteAt([]byte{3}, 110)
+	if data := b.Bytes(); len(data) != 111 || !slices.Equal(data[102:110], make([]byte, 8)) {
+		t.Fatalf("WriteAt past the end left % x", data[100:])
+	}
+}
+
+func TestWriteAtConcurrentWrite(t *testing.T) {
+	const n = 1000
+	b := NewBuffer("concurrent")
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < n; i++ {
+			b.Write([]byte{'a'})
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := n; i < 2*n; i++ {
+			b.WriteAt([]byte{'b'}, int64(i))
+		}
+	}()
+	wg.Wait()
+	if b.Tell() != n {
+		t.Fatalf("WriteAt disturbed the offset, which is %d", b.Tell())
+	}
+	data := b.Bytes()
+	if len(data) != 2*n {
+		t.Fatalf("length is %d, want %d", len(data), 2*n)
+	}
+	for i, c := range data {
+		if want := byte('a') + byte(i/n); c != want {
+			t.Fatalf("byte %d is %q, want %q", i, c, want)
+		}
+	}
+}
+
+func TestDetach(t *testing.T) {
+	b := NewBuffer("detach", []byte("before"))
+	ref := b.Reference().Reference()
+	ref.Seek(2, io.SeekStart)
+	ref.Detach()
+	b.WriteAt([]byte("AFTER!!"), 0)
+	if got := ref.String(); got != "before" || ref.Tell() != 2 {
+		t.Fatalf("detached reference reads %q at %d", got, ref.Tell())
+	}
+	ref.Write([]byte("xx"))
+	if got := b.String(); got != "AFTER!!" {
+		t.Fatalf("write to a detached reference reached the parent, which reads %q", got)
+	}
+}
+
+func TestCloneReference(t *testing.T) {
+	b := NewBuffer("clone", []byte("parent bytes"))
+	b.SetByteOrder(binary.BigEndian)
+	ref := b.Reference()
+	ref.SetByteOrder(binary.BigEndian)
+	ref.Seek(7, io.SeekStart)
+	clone := ref.Clone()
+	if clone.parent != nil || clone.name != "clone" || clone.Tell() != 7 {
+		t.Fatalf("clone has parent %p, name %q and offset %d", clone.parent, clone.name, clone.Tell())
+	}
+	b.WriteAt([]byte("PARENT"), 0)
+	b.Truncate(6)
+	if got := clone.String(); got != "parent bytes" {
+		t.Fatalf("clone reads %q after the parent changed", got)
+	}
+	if v, err := clone.ReadU16(); v != 'b'<<8|'y' || err != nil {
+		t.Fatalf("clone ReadU16 = %#x, %v, want the big-endian byte order", v, err)
+	}
+	clone.WriteAt([]byte("!"), 0)
+	if got := b.String(); got != "PARENT" {
+		t.Fatalf("write to the clone reached the parent, which reads %q", got)
+	}
+}
+
+func TestCopyInto(t *testing.T) {
+	src := NewBuffer("src", []byte("payload"))
+	src.Seek(3, io.SeekStart)
+	dst := NewBuffer("dst", []byte("a much longer leftover"))
+	dst.EnsureCapacity(64)
+	dst.Seek(5, io.SeekStart)
+	backing, capacity := dst.Buffer(), dst.Cap()
+	grows := 0
+	dst.SetCapacityWatcher(func(oldCap, newCap int64) { grows++ })
+	if err := src.CopyInto(dst); err != nil {
+		t.Fatal(err)
+	}
+	if got := dst.String(); got != "payload" || dst.Tell() != 0 || dst.Size() != 7 {
+		t.Fatalf("CopyInto left %q at offset %d with Size %d", got, dst.Tell(), dst.Size())
+	}
+	if dst.Buffer() != backing || dst.Cap() != capacity || grows != 0 {
+		t.Fatalf("CopyInto reallocated, Cap %d -> %d after %d grows", capacity, dst.Cap(), grows)
+	}
+	if src.Tell() != 3 || dst.name != "dst" {
+		t.Fatalf("CopyInto moved the source to %d and renamed dst to %q", src.Tell(), dst.name)
+	}
+	dst.Write([]byte("P"))
+	if src.String() != "payload" {
+		t.Fatalf("write to the copy reached the source, which holds %q", src.String())
+	}
+	if err := src.CopyInto(dst.Reference()); err == nil {
+		t.Fatal("CopyInto a reference succeeded")
+	}
+}
+
+func TestDeepReferenceChain(t *testing.T) {
+	b := NewBuffer("chain", []byte("root"))
+	ref := b
+	for i := 0; i < 100000; i++ {
+		ref = ref.Reference()
+	}
+	if ref.Closed() {
+		t.Fatal("deep reference reports closed")
+	}
+	if ref.Buffer() != b.Buffer() || ref.String() != "root" {
+		t.Fatalf("deep reference reads %q", ref.String())
+	}
+	ref.Seek(2, io.SeekStart)
+	ref.Reset()
+	if ref.Tell() != 0 || b.String() != "root" {
+		t.Fatalf("Reset on a deep reference left it at %d and the root holding %q", ref.Tell(), b.String())
+	}
+	b.Close()
+	if !ref.Closed() {
+		t.Fatal("deep reference missed the root closing")
+	}
+}
+
+func TestReferenceCycle(t *testing.T) {
+	a, b := NewBuffer("a"), NewBuffer("b")
+	a.parent, b.parent = b, a
+	defer func() {
+		if recover() == nil {
+			t.Fatal("resolving a cyclic reference chain did not panic")
+		}
+	}()
+	a.Closed()
+}
+
+func TestResetReference(t *testing.T) {
+	b := NewBuffer("reset", []byte("shared"))
+	ref, sibling := b.Reference(), b.Reference()
+	ref.Seek(4, io.SeekStart)
+	sibling.Seek(2, io.SeekStart)
+	ref.Reset()
+	if ref.Tell() != 0 || ref.String() != "shared" {
+		t.Fatalf("Reset left the reference at %d holding %q", ref.Tell(), ref.String())
+	}
+	if b.String() != "shared" || sibling.String() != "shared" || sibling.Tell() != 2 {
+		t.Fatalf("Reset on a reference changed the parent to %q and its sibling to %q at %d", b.String(), sibling.String(), sibling.Tell())
+	}
+}
+
+func TestWriteAbstractAfterSeek(t *testing.T) {
+	b := NewBuffer("abstract", []byte("0123456789"))
+	b.Seek(3, io.SeekStart)
+	if n, err := b.WriteAbstract([]string{"ab", "", "c"}); n != 3 || err != nil {
+		t.Fatalf("WriteAbstract = %d, %v", n, err)
+	}
+	if got := b.String(); got != "012abc6789" || b.Tell() != 6 {
+		t.Fatalf("WriteAbstract after Seek left %q at offset %d", got, b.Tell())
+	}
+	b.Seek(8, io.SeekStart)
+	b.WriteAbstract([]string{"xyz"})
+	if got := b.String(); got != "012abc67xyz" {
+		t.Fatalf("WriteAbstract across the end left %q", got)
+	}
+}
+
+// readOnlyMutations lists the operations that must fail with ErrReadOnly on a
+// read-only buffer
+var readOnlyMutations = map[string]func(*Buffer) error{
+	"Write":              func(b *Buffer) error { _, err := b.Write([]byte("x")); return err },
+	"WriteOffset":        func(b *Buffer) error { _, err := b.WriteOffset([]byte("x"), 0); return err },
+	"WriteAt":            func(b *Buffer) error { _, err := b.WriteAt([]byte("x"), 0); return err },
+	"WriteAbstract":      func(b *Buffer) error { _, err := b.WriteAbstract(uint8(1)); return err },
+	"WriteString":        func(b *Buffer) error { _, err := b.WriteString("x"); return err },
+	"WriteByte":          func(b *Buffer) error { return b.WriteByte('x') },
+	"WriteVectored":      func(b *Buffer) error { _, err := b.WriteVectored([]byte("x")); return err },
+	"WriteBits":          func(b *Buffer) error { return b.WriteBits(1, 1) },
+	"Truncate":           func(b *Buffer) error { return b.Truncate(1) },
+	"Insert":             func(b *Buffer) error { return b.Insert(0, []byte("x")) },
+	"Delete":             func(b *Buffer) error { return b.Delete(0, 1) },
+	"Fill":               func(b *Buffer) error { return b.Fill(0, 1, 'x') },
+	"ZeroRange":          func(b *Buffer) error { return b.ZeroRange(0, 1) },
+	"Wipe":               func(b *Buffer) error { return b.Wipe() },
+	"Merge":              func(b *Buffer) error { return b.Merge(NewBuffer("other", []byte("x"))) },
+	"Compact":            func(b *Buffer) error { return b.Compact() },
+	"Encrypt":            func(b *Buffer) error { return b.Encrypt(make([]byte, 16), make([]byte, 16)) },
+	"ResetParent":        func(b *Buffer) error { return b.ResetParent() },
+	"Reference.Write":    func(b *Buffer) error { _, err := b.Reference().Write([]byte("x")); return err },
+	"ReferenceCOW.Write": func(b *Buffer) error { _, err := b.ReferenceCOW().Write([]byte("x")); return err },
+	"Replace":            func(b *Buffer) error { _, err := b.Replace([]byte("s"), []byte("x"), -1); return err },
+	"Reserve":            func(b *Buffer) error { _, err := b.Reserve(1); return err },
+	"Extend":             func(b *Buffer) error { _, err := b.Extend(1); return err },
+	"ReadFrom":           func(b *Buffer) error { _, err := b.ReadFrom(strings.NewReader("x")); return err },
+	"Slice.Write":        func(b *Buffer) error { _, err := b.Slice(0, 2).Write([]byte("x")); return err },
+}
+
+func TestReadOnly(t *testing.T) {
+	b := NewBuffer("readonly", []byte("shared bytes"))
+	ro := b.ReadOnly()
+	p := make([]byte, 6)
+	if n, err := ro.Read(p); n != 6 || err != nil || string(p) != "shared" {
+		t.Fatalf("Read = %d, %v, %q", n, err, p[:n])
+	}
+	if pos, err := ro.Seek(7, io.SeekStart); pos != 7 || err != nil {
+		t.Fatalf("Seek = %d, %v", pos, err)
+	}
+	if n, err := ro.ReadAt(p[:5], 7); n != 5 || err != nil || string(p[:5]) != "bytes" {
+		t.Fatalf("ReadAt = %d, %v, %q", n, err, p[:n])
+	}
+
+	for name, mutate := range readOnlyMutations {
+		if err := mutate(ro); !errors.Is(err, ErrReadOnly) {
+			t.Fatalf("%s on a read-only view = %v, want ErrReadOnly", name, err)
+		}
+	}
+	ro.Reset()
+	if got := b.String(); got != "shared bytes" {
+		t.Fatalf("read-only view changed its parent to %q", got)
+	}
+	if ro.Tell() != 0 {
+		t.Fatalf("Reset left a read-only view at %d", ro.Tell())
+	}
+	if _, err := b.Write([]byte("S")); err != nil || ro.String() != "Shared bytes" {
+		t.Fatalf("parent Write = %v, read-only view reads %q", err, ro.String())
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	b := NewBuffer("frozen", []byte("shared bytes"))
+	ref := b.Reference()
+	b.Freeze()
+	for name, mutate := range readOnlyMutations {
+		if err := mutate(b); !errors.Is(err, ErrReadOnly) {
+			t.Fatalf("%s on a frozen buffer = %v, want ErrReadOnly", name, err)
+		}
+		if err := mutate(ref); !errors.Is(err, ErrReadOnly) {
+			t.Fatalf("%s through a reference to a frozen buffer = %v, want ErrReadOnly", name, err)
+		}
+	}
+	if got := b.String(); got != "shared bytes" {
+		t.Fatalf("frozen buffer changed to %q", got)
+	}
+	if data, err := b.ReadBytes(' '); string(data) != "shared " || err != nil {
+		t.Fatalf("ReadBytes on a frozen buffer = %q, %v", data, err)
+	}
+}
+
+func TestReferenceRO(t *testing.T) {
+	b := NewBuffer("parent", []byte("shared bytes"))
+	for name, mutate := range readOnlyMutations {
+		if err := mutate(b.ReferenceRO()); !errors.Is(err, ErrReadOnly) {
+			t.Fatalf("%s through ReferenceRO = %v, want ErrReadOnly", name, err)
+		}
+	}
+	if got := b.String(); got != "shared bytes" {
+		t.Fatalf("ReferenceRO changed its parent to %q", got)
+	}
+	if _, err := b.Write([]byte("S")); err != nil {
+		t.Fatalf("Write on the parent of a ReferenceRO = %v", err)
+	}
+}
+
+func TestWithByteOrder(t *testing.T) {
+	b := NewBuffer("order")
+	if got := b.WithByteOrder(binary.BigEndian); got != b {
+		t.Fatal("WithByteOrder returned a different buffer")
+	}
+	b.WriteU32(0x01020304)
+	b.WriteAbstract(uint16(0x0506))
+	if got := b.Bytes(); !slices.Equal(got, []byte{1, 2, 3, 4, 5, 6}) {
+		t.Fatalf("big-endian writes encoded % x", got)
+	}
+	ref := b.Reference()
+	if v, err := ref.ReadU16(); v != 0x0102 || err != nil {
+		t.Fatalf("reference ReadU16 = %#x, %v, want the parent's big-endian order", v, err)
+	}
+
+	b.WithByteOrder(nil)
+	b.Seek(0, io.SeekStart)
+	if v, err := b.ReadU32(); v != 0x04030201 || err != nil {
+		t.Fatalf("ReadU32 after WithByteOrder(nil) = %#x, %v, want little-endian", v, err)
+	}
+	if v, err := b.ReadU16BE(); v != 0x0506 || err != nil {
+		t.Fatalf("ReadU16BE = %#x, %v, want the explicit order to win", v, err)
+	}
+}
+
+func TestCapacityCache(t *testing.T) {
+	b := NewBuffer("capacity", []byte("abc"))
+	ref := b.Reference()
+	check := func(step string) {
+		t.Helper()
+		if b.capacity != b.buffer.ByteCapacity() {
+			t.Fatalf("%s: cached capacity %d, crunch reports %d", step, b.capacity, b.buffer.ByteCapacity())
+		}
+	}
+	check("NewBuffer")
+	b.Seek(0, io.SeekEnd)
+	b.Write([]byte("defgh"))
+	check("Write")
+	ref.WriteAt([]byte("ijkl"), 8)
+	check("reference WriteAt")
+	if ref.Cap() != b.Cap() || ref.Size() != 12 {
+		t.Fatalf("reference sees Cap %d and Size %d after the parent grew", ref.Cap(), ref.Size())
+	}
+	b.Truncate(2)
+	check("Truncate")
+	b.Shrink()
+	check("Shrink")
+	b.EnsureCapacity(100)
+	check("EnsureCapacity")
+	b.Reset()
+	check("Reset")
+}
+
+func BenchmarkRead(b *testing.B) {
+	buffer := NewBuffer("read", benchmarkData)
+	p := make([]byte, 16)
+	b.SetBytes(int64(len(p)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := buffer.Read(p); err == io.EOF {
+			buffer.Seek(0, io.SeekStart)
+		}
+	}
+}
+
+func BenchmarkReadReference(b *testing.B) {
+	buffer := NewBuffer("read", benchmarkData).Reference()
+	p := make([]byte, 16)
+	b.SetBytes(int64(len(p)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := buffer.Read(p); err == io.EOF {
+			buffer.Seek(0, io.SeekStart)
+		}
+	}
+}
+
+func TestWriteAbstractNil(t *testing.T) {
+	b := NewBuffer("abstract", []byte("data"))
+	var nilReader io.Reader
+	for _, v := range []any{
+		nil,
+		(*int)(nil),
+		(*bytes.Buffer)(nil),
+		(*bytes.Reader)(nil),
+		nilReader,
+		(func())(nil),
+	} {
+		if n, err := b.WriteAbstract(v); n != 0 || !errors.Is(err, ErrNilAbstract) {
+			t.Fatalf("WriteAbstract(%T) = %d, %v, want ErrNilAbstract", v, n, err)
+		}
+		if _, err := b.WriteAbstractAt(2, v); !errors.Is(err, ErrNilAbstract) {
+			t.Fatalf("WriteAbstractAt(%T) = %v, want ErrNilAbstract", v, err)
+		}
+	}
+	if got := b.String(); got != "data" || b.Tell() != 0 {
+		t.Fatalf("nil writes left %q at offset %d", got, b.Tell())
+	}
+	if n, err := b.WriteAbstract([]byte(nil)); n != 0 || err != nil {
+		t.Fatalf("WriteAbstract of a nil slice = %d, %v, want an empty write", n, err)
+	}
+}
+
+func TestStrictBounds(t *testing.T) {
+	p := make([]byte, 4)
+	b := NewBuffer("strict", []byte("data"))
+	if pos, err := b.Seek(10, io.SeekStart); pos != 10 || err != nil {
+		t.Fatalf("lenient Seek past the end = %d, %v", pos, err)
+	}
+	if n, err := b.Read(p); n != 0 || err != io.EOF || b.Tell() != 4 {
+		t.Fatalf("lenient Read past the end = %d, %v at %d, want 0, io.EOF at 4", n, err, b.Tell())
+	}
+
+	b = NewBuffer("strict", []byte("data"))
+	b.SetStrict(true)
+	if _, err := b.Seek(10, io.SeekStart); !errors.Is(err, io.ErrUnexpectedEOF) || b.Tell() != 0 {
+		t.Fatalf("strict Seek past the end = %v, offset %d, want io.ErrUnexpectedEOF", err, b.Tell())
+	}
+	if pos, err := b.Seek(4, io.SeekStart); pos != 4 || err != nil {
+		t.Fatalf("strict Seek to the end = %d, %v", pos, err)
+	}
+	if n, err := b.Read(p); n != 0 || err != io.EOF {
+		t.Fatalf("strict Read at the end = %d, %v, want 0, io.EOF", n, err)
+	}
+	b.SetStrict(false)
+	b.Seek(10, io.SeekStart)
+	b.SetStrict(true)
+	if n, err := b.Read(p); n != 0 || !errors.Is(err, io.ErrUnexpectedEOF) || b.Tell() != 10 {
+		t.Fatalf("strict Read past the end = %d, %v at %d, want io.ErrUnexpectedEOF", n, err, b.Tell())
+	}
+	if ref := b.Reference(); !ref.strict {
+		t.Fatal("reference of a strict buffer is lenient")
+	}
+}
+
+func TestWriteAbstractAt(t *testing.T) {
+	b := NewBuffer("abstract")
+	b.SetByteOrder(binary.BigEndian)
+	b.Write([]byte("HDR\x00\x00\x00\x00body"))
+	if n, err := b.WriteAbstractAt(3, uint32(0xDEADBEEF)); n != 4 || err != nil {
+		t.Fatalf("WriteAbstractAt = %d, %v", n, err)
+	}
+	if got, want := b.Bytes(), []byte("HDR\xDE\xAD\xBE\xEFbody"); !slices.Equal(got, want) {
+		t.Fatalf("WriteAbstractAt left % x, want % x", got, want)
+	}
+	if b.Tell() != 11 {
+		t.Fatalf("WriteAbstractAt moved the offset to %d", b.Tell())
+	}
+	b.Seek(3, io.SeekStart)
+	if v, err := b.ReadU32(); v != 0xDEADBEEF || err != nil {
+		t.Fatalf("patched field reads %#x, %v", v, err)
+	}
+	if _, err := b.Reference().WriteAbstractAt(11, uint16(0x0102)); err != nil || b.Size() != 13 {
+		t.Fatalf("WriteAbstractAt past the end through a reference = %v, Size %d", err, b.Size())
+	}
+	if _, err := b.WriteAbstractAt(-1, uint8(1)); err == nil {
+		t.Fatal("WriteAbstractAt(-1) succeeded")
+	}
+}
+
+func TestZeroValue(t *testing.T) {
+	for name, op := range map[string]func(b *Buffer) error{
+		"query": func(b *Buffer) error {
+			if b.Closed() || b.Size() != 0 || b.Len() != 0 || b.Cap() != 0 || b.Remaining() != 0 || b.String() != "" || len(b.Bytes()) != 0 {
+				return errors.New("zero value is not empty")
+			}
+			return nil
+		},
+		"Read": func(b *Buffer) error {
+			if n, err := b.Read(make([]byte, 1)); n != 0 || err != io.EOF {
+				return fmt.Errorf("Read = %d, %v, want 0, io.EOF", n, err)
+			}
+			return nil
+		},
+		"Write": func(b *Buffer) error {
+			if _, err := b.Write([]byte("hi")); err != nil || b.String() != "hi" {
+				return fmt.Errorf("Write = %v, holds %q", err, b.String())
+			}
+			return nil
+		},
+		"WriteAbstract": func(b *Buffer) error {
+			if _, err := b.WriteAbstract(uint16(5)); err != nil || b.Size() != 2 {
+				return fmt.Errorf("WriteAbstract = %v, Size %d", err, b.Size())
+			}
+			return nil
+		},
+		"WriteAt": func(b *Buffer) error {
+			if _, err := b.WriteAt([]byte("x"), 3); err != nil || b.Size() != 4 {
+				return fmt.Errorf("WriteAt = %v, Size %d", err, b.Size())
+			}
+			return nil
+		},
+		"Seek": func(b *Buffer) error {
+			if pos, err := b.Seek(0, io.SeekEnd); pos != 0 || err != nil {
+				return fmt.Errorf("Seek = %d, %v", pos, err)
+			}
+			return nil
+		},
+		"Reference": func(b *Buffer) error {
+			if _, err := b.Reference().Write([]byte("ab")); err != nil || b.String() != "ab" {
+				return fmt.Errorf("reference Write = %v, parent holds %q", err, b.String())
+			}
+			return nil
+		},
+		"NewReader": func(b *Buffer) error {
+			if n, err := b.NewReader().Read(make([]byte, 1)); n != 0 || err != io.EOF {
+				return fmt.Errorf("reader Read = %d, %v, want 0, io.EOF", n, err)
+			}
+			return nil
+		},
+		"Copy": func(b *Buffer) error {
+			if b.Copy().Size() != 0 || b.Clone().Size() != 0 {
+				return errors.New("copies of the zero value are not empty")
+			}
+			return nil
+		},
+		"capacity": func(b *Buffer) error {
+			if err := b.EnsureCapacity(8); err != nil || b.Available() < 8 {
+				return fmt.Errorf("EnsureCapacity = %v, Available %d", err, b.Available())
+			}
+			return b.Shrink()
+		},
+		"Truncate": func(b *Buffer) error { return b.Truncate(0) },
+		"Wipe":     func(b *Buffer) error { return b.Wipe() },
+		"Reset": func(b *Buffer) error {
+			b.Reset()
+			return nil
+		},
+		"Close": func(b *Buffer) error {
+			if err := b.Close(); err != nil || !b.Closed() {
+				return fmt.Errorf("Close = %v, Closed %v", err, b.Closed())
+			}
+			return nil
+		},
+	} {
+		var b Buffer
+		if err := op(&b); err != nil {
+			t.Fatalf("%s on a zero Buffer: %v", name, err)
+		}
+	}
+}
+
+func TestReferenceSeekEnd(t *testing.T) {
+	b := NewBuffer("seek", []byte("short"))
+	ref := b.Reference()
+	if pos, _ := ref.Seek(0, io.SeekEnd); pos != 5 {
+		t.Fatalf("reference SeekEnd = %d, want 5", pos)
+	}
+	b.Seek(0, io.SeekEnd)
+	b.Write([]byte(" and grown"))
+	if pos, err := ref.Seek(0, io.SeekEnd); pos != 15 || err != nil {
+		t.Fatalf("reference SeekEnd after the parent grew = %d, %v, want 15", pos, err)
+	}
+	if n, err := ref.Read(make([]byte, 4)); n != 0 || err != io.EOF {
+		t.Fatalf("Read at the end = %d, %v, want 0, io.EOF", n, err)
+	}
+	b.Truncate(3)
+	if pos, _ := ref.Seek(0, io.SeekEnd); pos != 3 {
+		t.Fatalf("reference SeekEnd after the parent shrank = %d, want 3", pos)
+	}
+}
+
+func TestSizeConcurrentWrite(t *testing.T) {
+	b := NewBuffer("size")
+	ref := b.Reference()
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for r := 0; r < 8; r++ {
+		wg.Add(1)
+		go func(target *Buffer) {
+			defer wg.Done()
+			last := int64(0)
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				size := target.Size()
+				if size < last || int64(target.Len()) > target.Size() || target.ByteCapacity() < last {
+					t.Errorf("Size went from %d to %d", last, size)
+					return
+				}
+				last = size
+			}
+		}([]*Buffer{b, ref}[r%2])
+	}
+	for i := 0; i < 2000; i++ {
+		b.Write([]byte("x"))
+	}
+	close(done)
+	wg.Wait()
+	if b.Size() != 2000 || b.Len() != 0 || ref.Len() != 2000 {
+		t.Fatalf("Size, Len and reference Len = %d, %d, %d", b.Size(), b.Len(), ref.Len())
+	}
+}
+
+func TestWriteAbstractAll(t *testing.T) {
+	b := NewBuffer("abstract")
+	b.SetByteOrder(binary.BigEndian)
+	n, err := b.WriteAbstractAll([]byte("HDR"), uint8(2), uint16(0x0102), uint32(0xCAFEBABE), "name", true)
+	want := []byte("HDR\x02\x01\x02\xCA\xFE\xBA\xBEname\x01")
+	if n != len(want) || err != nil {
+		t.Fatalf("WriteAbstractAll = %d, %v, want %d, nil", n, err, len(want))
+	}
+	if got := b.Bytes(); !slices.Equal(got, want) || b.Tell() != int64(len(want)) {
+		t.Fatalf("WriteAbstractAll left % x at offset %d, want % x", got, b.Tell(), want)
+	}
+
+	b = NewBuffer("abstract")
+	n, err = b.WriteAbstractAll("ok", uint8(1), struct{}{}, "never")
+	if n != 3 || err == nil || b.String() != "ok\x01" {
+		t.Fatalf("WriteAbstractAll with an unsupported value = %d, %v, left %q", n, err, b.String())
+	}
+	if n, err := b.WriteAbstractAll(nil); n != 0 || !errors.Is(err, ErrNilAbstract) || b.Size() != 3 {
+		t.Fatalf("WriteAbstractAll(nil) = %d, %v, Size %d", n, err, b.Size())
+	}
+}
+
+var (
+	_ io.ReaderAt = (*Buffer)(nil)
+	_ io.WriterAt = (*Buffer)(nil)
+)
+
+func TestReadAtWriteAt(t *testing.T) {
+	b := NewBuffer("at", []byte("hello world"))
+	b.Seek(3, io.SeekStart)
+	p := make([]byte, 5)
+	if n, err := b.ReadAt(p, 6); n != 5 || err != nil || string(p) != "world" {
+		t.Fatalf("ReadAt = %d, %v, %q", n, err, p)
+	}
+	if n, err := b.ReadAt(p, 8); n != 3 || err != io.EOF || string(p[:n]) != "rld" {
+		t.Fatalf("short ReadAt = %d, %v, %q, want 3, io.EOF", n, err, p[:n])
+	}
+	if n, err := b.ReadAt(p, 20); n != 0 || err != io.EOF {
+		t.Fatalf("ReadAt past end = %d, %v, want 0, io.EOF", n, err)
+	}
+	if _, err := b.ReadAt(p, -1); err == nil {
+		t.Fatal("ReadAt at a negative offset succeeded")
+	}
+	if n, err := b.WriteAt([]byte("W"), 6); n != 1 || err != nil {
+		t.Fatalf("WriteAt = %d, %v", n, err)
+	}
+	if b.Tell() != 3 {
+		t.Fatalf("offset moved to %d", b.Tell())
+	}
+	data, err := io.ReadAll(io.NewSectionReader(b, 6, 5))
+	if err != nil || string(data) != "World" {
+		t.Fatalf("section read %q, %v", data, err)
+	}
+}
+
+func TestReadAtReference(t *testing.T) {
+	b := NewBuffer("at", []byte("parent"))
+	ref := b.Reference()
+	b.Write([]byte("PA"))
+	p := make([]byte, 6)
+	if n, err := ref.ReadAt(p, 0); n != 6 || err != nil || string(p) != "PArent" {
+		t.Fatalf("reference ReadAt = %d, %v, %q", n, err, p)
+	}
+}
+
+func TestReadAtReferenceConcurrentWrite(t *testing.T) {
+	b := NewBuffer("at")
+	ref := b.Reference()
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			b.Write([]byte("0123456789"))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		p := make([]byte, 10)
+		for i := 0; i < 1000; i++ {
+			if n, err := ref.ReadAt(p, 0); err == nil && string(p[:n]) != "0123456789" {
+				t.Errorf("reference read %q", p[:n])
+				return
+			}
+		}
+	}()
+	wg.Wait()
+}
+
+func TestTellRemaining(t *testing.T) {
+	b := NewBuffer("tell", []byte("0123456789"))
+	if b.Tell() != 0 || b.Remaining() != 10 {
+		t.Fatalf("fresh buffer at %d with %d remaining", b.Tell(), b.Remaining())
+	}
+	b.Read(make([]byte, 3))
+	if b.Tell() != 3 || b.Remaining() != 7 {
+		t.Fatalf("after read at %d with %d remaining", b.Tell(), b.Remaining())
+	}
+	b.Seek(5, io.SeekCurrent)
+	if b.Tell() != 8 || b.Remaining() != 2 {
+		t.Fatalf("after SeekCurrent at %d with %d remaining", b.Tell(), b.Remaining())
+	}
+	b.Seek(15, io.SeekStart)
+	if b.Tell() != 15 || b.Remaining() != 0 {
+		t.Fatalf("past the end at %d with %d remaining", b.Tell(), b.Remaining())
+	}
+
+	ref := b.Reference()
+	ref.Seek(4, io.SeekStart)
+	ref.Read(make([]byte, 2))
+	if ref.Tell() != 6 || ref.Remaining() != 4 {
+		t.Fatalf("reference at %d with %d remaining", ref.Tell(), ref.Remaining())
+	}
+	b.WriteAt([]byte("abc"), 10)
+	if ref.Remaining() != 7 {
+		t.Fatalf("reference sees %d remaining after the parent grew, want 7", ref.Remaining())
+	}
+	if b.Tell() != 15 {
+		t.Fatalf("reference moved the parent to %d", b.Tell())
+	}
+}
+
+func TestRemainingConcurrentWrite(t *testing.T) {
+	b := NewBuffer("tell")
+	ref := b.Reference()
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			b.Write([]byte("x"))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		last := int64(0)
+		for i := 0; i < 1000; i++ {
+			remaining := ref.Remaining()
+			if remaining < last {
+				t.Errorf("Remaining went from %d to %d", last, remaining)
+				return
+			}
+			last = remaining
+		}
+	}()
+	wg.Wait()
+}
+
+func TestWriteAbstract(t *testing.T) {
+	b := NewBuffer("abstract")
+	b.SetByteOrder(binary.BigEndian)
+	src := []byte{1, 2}
+	for _, v := range []any{
+		uint16(0x0304),
+		src,
+		"ab",
+		[]string{"c", "de"},
+		[]uint16{0x0506},
+		bytes.NewReader([]byte{7}),
+		bytes.NewBuffer([]byte{8}),
+		true,
+	} {
+		if _, err := b.WriteAbstract(v); err != nil {
+			t.Fatalf("WriteAbstract(%T) = %v", v, err)
+		}
+	}
+	src[0] = 0xFF
+	want := []byte{3, 4, 1, 2, 'a', 'b', 'c', 'd', 'e', 5, 6, 7, 8, 1}
+	if got := b.Bytes(); !slices.Equal(got, want) {
+		t.Fatalf("WriteAbstract wrote % x, want % x", got, want)
+	}
+	if _, err := b.WriteAbstract(struct{}{}); err == nil {
+		t.Fatal("WriteAbstract of an unsupported type succeeded")
+	}
+	if _, err := b.WriteAbstract(nil); !errors.Is(err, ErrNilAbstract) {
+		t.Fatalf("WriteAbstract(nil) = %v, want ErrNilAbstract", err)
+	}
+}
+
+func BenchmarkWriteAbstractScalar(b *testing.B) {
+	buffer := NewBuffer("abstract")
+	var v any = uint32(0x01020304)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if i%4096 == 0 {
+			buffer.Seek(0, io.SeekStart)
+		}
+		buffer.WriteAbstract(v)
+	}
+}
+
+func BenchmarkWriteAbstractBytes(b *testing.B) {
+	buffer := NewBuffer("abstract")
+	var data any = make([]byte, 64)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if i%256 == 0 {
+			buffer.Seek(0, io.SeekStart)
+		}
+		buffer.WriteAbstract(data)
+	}
+}
+
+func TestReferenceCOW(t *testing.T) {
+	b := NewBuffer("cow", []byte("shared"))
+	cow := b.ReferenceCOW()
+	if got := cow.String(); got != "shared" {
+		t.Fatalf("COW reference reads %q", got)
+	}
+	b.WriteAt([]byte("S"), 0)
+	if got := cow.String(); got != "Shared" {
+		t.Fatalf("COW reference missed a write to its source, reads %q", got)
+	}
+	if _, err := cow.WriteAt([]byte("X"), 1); err != nil {
+		t.Fatal(err)
+	}
+	if got := cow.String(); got != "SXared" {
+		t.Fatalf("COW reference reads %q after its write", got)
+	}
+	if got := b.String(); got != "Shared" {
+		t.Fatalf("COW write leaked into its source, which reads %q", got)
+	}
+}
+
+func TestResetParent(t *testing.T) {
+	b := NewBuffer("reset", []byte("shared"))
+	ref := b.Reference()
+	ref.Seek(3, io.SeekStart)
+	if err := ref.ResetParent(); err != nil {
+		t.Fatal(err)
+	}
+	if b.Size() != 0 || ref.Size() != 0 || ref.Tell() != 0 {
+		t.Fatalf("ResetParent left parent length %d, reference length %d and offset %d", b.Size(), ref.Size(), ref.Tell())
+	}
+	if err := b.ReadOnly().ResetParent(); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("ResetParent on a read-only reference = %v, want ErrReadOnly", err)
+	}
+}
+
+func TestResetParentCOW(t *testing.T) {
+	b := NewBuffer("reset", []byte("shared"))
+	cow := b.ReferenceCOW()
+	ref := cow.Reference()
+	if err := ref.ResetParent(); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != "shared" {
+		t.Fatalf("ResetParent through a COW reference wiped its source, which reads %q", got)
+	}
+	if cow.Size() != 0 || ref.Size() != 0 {
+		t.Fatalf("ResetParent left COW length %d and reference length %d", cow.Size(), ref.Size())
+	}
+	if err := b.ReferenceCOW().ResetParent(); err != nil || b.String() != "shared" {
+		t.Fatalf("ResetParent on a COW reference = %v, source reads %q", err, b.String())
+	}
+	if err := b.Slice(1, 2).ResetParent(); err == nil || b.String() != "shared" {
+		t.Fatalf("ResetParent on a slice = %v, source reads %q", err, b.String())
+	}
+}
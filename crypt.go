@@ -0,0 +1,69 @@
+package crunchio
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"fmt"
+	"math"
+)
+
+// Encrypt applies AES-CTR in place over the full contents of the buffer
+func (b *Buffer) Encrypt(key, iv []byte) error {
+	if b == nil {
+		panic("ENCRYPT: buffer is nil")
+	}
+	return b.cryptCTR("encrypt", key, iv, 0, math.MaxInt64)
+}
+
+// Decrypt reverses Encrypt, as AES-CTR is its own inverse
+func (b *Buffer) Decrypt(key, iv []byte) error {
+	if b == nil {
+		panic("DECRYPT: buffer is nil")
+	}
+	return b.cryptCTR("decrypt", key, iv, 0, math.MaxInt64)
+}
+
+// cryptCTR applies AES-CTR in place over the bytes in [start, end) clamped to
+// the length of the buffer, a slice narrowing the range to its window
+func (b *Buffer) cryptCTR(op string, key, iv []byte, start, end int64) error {
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return fmt.Errorf("buffer: %s: invalid AES key length %d", op, len(key))
+	}
+	if len(iv) != aes.BlockSize {
+		return fmt.Errorf("buffer: %s: invalid IV length %d", op, len(iv))
+	}
+	b.Lock()
+	defer b.Unlock()
+	if b.Closed() {
+		return fmt.Errorf("buffer: %s: %w", op, ErrClosed)
+	}
+	if b.readOnly {
+		return fmt.Errorf("buffer: %s: %w", op, ErrReadOnly)
+	}
+	b.unshare()
+	if b.parent != nil {
+		if b.windowed {
+			start, end = b.windowBase+start, b.windowBase+min(end, b.windowLen)
+		}
+		return b.parent.cryptCTR(op, key, iv, start, end)
+	}
+	buffer := b.Buffer()
+	if buffer == nil {
+		return fmt.Errorf("buffer: %s: crunch buffer vanished", op)
+	}
+	end = min(end, b.length)
+	if end <= start {
+		return nil
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return fmt.Errorf("buffer: %s: %w", op, err)
+	}
+	data := make([]byte, end-start)
+	cipher.NewCTR(block, iv).XORKeyStream(data, buffer.ReadBytes(start, end-start))
+	buffer.WriteBytes(start, data)
+	b.writes.Add(1)
+	return nil
+}
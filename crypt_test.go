@@ -0,0 +1,42 @@
+package crunchio
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	plain := []byte("attack at dawn, bring snacks")
+	key := bytes.Repeat([]byte{0x42}, 32)
+	iv := bytes.Repeat([]byte{0x07}, 16)
+	b := NewBuffer("crypt", bytes.Clone(plain))
+	if err := b.Encrypt(key, iv); err != nil {
+		t.Fatal(err)
+	}
+	cipherText := b.Bytes()
+	if len(cipherText) != len(plain) || bytes.Equal(cipherText, plain) {
+		t.Fatalf("Encrypt left % x", cipherText)
+	}
+	if err := b.Decrypt(key, iv); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.Bytes(); !bytes.Equal(got, plain) {
+		t.Fatalf("Decrypt = %q, want %q", got, plain)
+	}
+}
+
+func TestEncryptInvalid(t *testing.T) {
+	b := NewBuffer("crypt", []byte("data"))
+	if err := b.Encrypt(make([]byte, 15), make([]byte, 16)); err == nil {
+		t.Fatal("Encrypt with a 15-byte key succeeded")
+	}
+	if err := b.Encrypt(make([]byte, 16), make([]byte, 8)); err == nil {
+		t.Fatal("Encrypt with an 8-byte IV succeeded")
+	}
+	if err := b.ReadOnly().Encrypt(make([]byte, 16), make([]byte, 16)); err == nil {
+		t.Fatal("Encrypt through a read-only reference succeeded")
+	}
+	if b.String() != "data" {
+		t.Fatalf("failed Encrypt changed the buffer to %q", b.String())
+	}
+}
@@ -0,0 +1,89 @@
+package crunchio
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// cstringChunk is how many bytes ReadCString scans for a terminator at a time
+const cstringChunk = 64
+
+// WriteCString writes s followed by a NUL terminator at the current offset
+func (b *Buffer) WriteCString(s string) (int, error) {
+	if b == nil {
+		panic("WRITECSTRING: buffer is nil")
+	}
+	if strings.IndexByte(s, 0) >= 0 {
+		return 0, fmt.Errorf("buffer: writecstring: string contains a NUL byte")
+	}
+	data := make([]byte, len(s)+1)
+	copy(data, s)
+	return b.Write(data)
+}
+
+// ReadCString reads bytes up to and including the next NUL terminator,
+// returning them without the terminator, maxLen bounds how many bytes may
+// precede the terminator unless it is zero or negative, and nothing is
+// consumed on failure
+func (b *Buffer) ReadCString(maxLen int) (string, error) {
+	if b == nil {
+		panic("READCSTRING: buffer is nil")
+	}
+	at, data, err := b.readCString(maxLen)
+	if err != nil {
+		return "", err
+	}
+	b.afterRead(at, data)
+	return string(data[:len(data)-1]), nil
+}
+
+// readCString consumes the bytes of the NUL-terminated string at the current
+// offset, terminator included
+func (b *Buffer) readCString(maxLen int) (at int64, data []byte, err error) {
+	b.Lock()
+	defer b.Unlock()
+	if b.Closed() {
+		return 0, nil, fmt.Errorf("buffer: readcstring: %w", ErrClosed)
+	}
+	// the bit cursor is only aligned once the read succeeds, so a failure
+	// leaves it where it was
+	at = b.offset
+	if b.bit != 0 {
+		at++
+	}
+	var chunk [cstringChunk]byte
+	for {
+		var read int
+		if b.parent != nil {
+			window, from := b.windowRead(chunk[:], at+int64(len(data)))
+			read, err = b.parent.readOffset(window, from)
+		} else {
+			read, err = b.readOffsetLocked(chunk[:], at+int64(len(data)))
+		}
+		if err != nil && err != io.EOF {
+			return at, nil, err
+		}
+		if i := bytes.IndexByte(chunk[:read], 0); i >= 0 {
+			data = append(data, chunk[:i+1]...)
+			break
+		}
+		data = append(data, chunk[:read]...)
+		if maxLen > 0 && len(data) > maxLen {
+			return at, nil, fmt.Errorf("buffer: readcstring: no terminator within %d bytes", maxLen)
+		}
+		if read == 0 {
+			if len(data) == 0 {
+				return at, nil, io.EOF
+			}
+			return at, nil, io.ErrUnexpectedEOF
+		}
+	}
+	if maxLen > 0 && len(data)-1 > maxLen {
+		return at, nil, fmt.Errorf("buffer: readcstring: no terminator within %d bytes", maxLen)
+	}
+	b.offset = at + int64(len(data))
+	b.bit = 0
+	return at, data, nil
+}
@@ -0,0 +1,51 @@
+package crunchio
+
+import (
+	"io"
+	"testing"
+)
+
+func TestCStringRoundTrip(t *testing.T) {
+	b := NewBuffer("cstring")
+	for _, s := range []string{"hello", "", "a longer string that spans more than one scan chunk of sixty-four bytes"} {
+		if n, err := b.WriteCString(s); n != len(s)+1 || err != nil {
+			t.Fatalf("WriteCString(%q) = %d, %v", s, n, err)
+		}
+	}
+	b.Seek(0, io.SeekStart)
+	for _, want := range []string{"hello", "", "a longer string that spans more than one scan chunk of sixty-four bytes"} {
+		if got, err := b.ReadCString(0); got != want || err != nil {
+			t.Fatalf("ReadCString = %q, %v, want %q", got, err, want)
+		}
+	}
+	if _, err := b.ReadCString(0); err != io.EOF {
+		t.Fatalf("ReadCString at the end = %v, want io.EOF", err)
+	}
+}
+
+func TestCStringEmbeddedNUL(t *testing.T) {
+	b := NewBuffer("cstring")
+	if n, err := b.WriteCString("a\x00b"); n != 0 || err == nil {
+		t.Fatalf("WriteCString with an embedded NUL = %d, %v", n, err)
+	}
+	if b.Size() != 0 {
+		t.Fatalf("failed WriteCString wrote %d bytes", b.Size())
+	}
+}
+
+func TestCStringFailureRestoresOffset(t *testing.T) {
+	b := NewBuffer("cstring", []byte("xunterminated"))
+	b.ReadU8()
+	if _, err := b.ReadCString(0); err != io.ErrUnexpectedEOF || b.Tell() != 1 {
+		t.Fatalf("ReadCString without a terminator = %v at offset %d, want io.ErrUnexpectedEOF at 1", err, b.Tell())
+	}
+
+	b = NewBuffer("cstring", []byte("xtoolong\x00"))
+	b.ReadBit()
+	if _, err := b.ReadCString(4); err == nil || b.TellBit() != 1 {
+		t.Fatalf("ReadCString past maxLen = %v at bit %d, want an error at bit 1", err, b.TellBit())
+	}
+	if got, err := b.ReadCString(7); got != "toolong" || err != nil || b.Tell() != 9 {
+		t.Fatalf("ReadCString mid-byte = %q, %v, offset %d", got, err, b.Tell())
+	}
+}
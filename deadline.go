@@ -0,0 +1,60 @@
+package crunchio
+
+import (
+	"os"
+	"time"
+)
+
+// SetReadDeadline makes Read fail with os.ErrDeadlineExceeded once t has
+// passed, a zero t disables the deadline
+func (b *Buffer) SetReadDeadline(t time.Time) {
+	if b == nil {
+		panic("SETREADDEADLINE: buffer is nil")
+	}
+	b.readDeadline.Store(deadlineNanos(t))
+}
+
+// SetWriteDeadline makes Write fail with os.ErrDeadlineExceeded once t has
+// passed, a zero t disables the deadline
+func (b *Buffer) SetWriteDeadline(t time.Time) {
+	if b == nil {
+		panic("SETWRITEDEADLINE: buffer is nil")
+	}
+	b.writeDeadline.Store(deadlineNanos(t))
+}
+
+// SetDeadline sets both the read and write deadlines
+func (b *Buffer) SetDeadline(t time.Time) {
+	if b == nil {
+		panic("SETDEADLINE: buffer is nil")
+	}
+	b.SetReadDeadline(t)
+	b.SetWriteDeadline(t)
+}
+
+func deadlineNanos(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+// lockUntil acquires the lock, giving up with os.ErrDeadlineExceeded if the
+// deadline in Unix nanoseconds passes first, a zero deadline waits forever
+func (b *Buffer) lockUntil(deadline int64) error {
+	if deadline == 0 {
+		b.Lock()
+		return nil
+	}
+	for wait := time.Microsecond; !b.TryLock(); wait = min(2*wait, time.Millisecond) {
+		if time.Now().UnixNano() >= deadline {
+			return os.ErrDeadlineExceeded
+		}
+		time.Sleep(wait)
+	}
+	if time.Now().UnixNano() >= deadline {
+		b.Unlock()
+		return os.ErrDeadlineExceeded
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package crunchio
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestDeadlineElapsed(t *testing.T) {
+	b := NewBuffer("deadline", []byte("data"))
+	b.SetDeadline(time.Now().Add(-time.Second))
+	if n, err := b.Read(make([]byte, 2)); n != 0 || !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("Read past the deadline = %d, %v, want os.ErrDeadlineExceeded", n, err)
+	}
+	if n, err := b.Write([]byte("xx")); n != 0 || !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("Write past the deadline = %d, %v, want os.ErrDeadlineExceeded", n, err)
+	}
+	if b.String() != "data" || b.Tell() != 0 {
+		t.Fatalf("timed out operations left %q at offset %d", b.String(), b.Tell())
+	}
+	b.SetReadDeadline(time.Time{})
+	if n, err := b.Read(make([]byte, 2)); n != 2 || err != nil {
+		t.Fatalf("Read with the deadline cleared = %d, %v", n, err)
+	}
+	if _, err := b.Write([]byte("xx")); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("Write with only the read deadline cleared = %v", err)
+	}
+}
+
+func TestDeadlineWhileLocked(t *testing.T) {
+	b := NewBuffer("deadline", []byte("data"))
+	b.Lock()
+	defer b.Unlock()
+	b.SetWriteDeadline(time.Now().Add(10 * time.Millisecond))
+	start := time.Now()
+	if _, err := b.Write([]byte("x")); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("Write blocked on the lock = %v, want os.ErrDeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Write gave up after %v", elapsed)
+	}
+}
@@ -0,0 +1,82 @@
+package crunchio
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// ReadBytes reads up to and including the first delim at the current offset,
+// like bufio.Reader.ReadBytes, returning the rest of the buffer along with
+// io.EOF if delim is not found
+func (b *Buffer) ReadBytes(delim byte) ([]byte, error) {
+	if b == nil {
+		panic("READBYTES: buffer is nil")
+	}
+	at, data, found, err := b.readBytes(delim)
+	if err != nil {
+		return nil, err
+	}
+	b.afterRead(at, data)
+	if len(data) == 0 {
+		return nil, io.EOF
+	}
+	if !found {
+		return data, io.EOF
+	}
+	return data, nil
+}
+
+// readBytes aligns the bit cursor, searches for delim and reads up to it under
+// a single lock, so a concurrent read can't move the offset in between
+func (b *Buffer) readBytes(delim byte) (at int64, data []byte, found bool, err error) {
+	b.Lock()
+	defer b.Unlock()
+	if b.Closed() {
+		return 0, nil, false, fmt.Errorf("buffer: readbytes: %w", ErrClosed)
+	}
+	b.clampTruncated()
+	b.alignBit()
+	at = b.offset
+	length := b.lengthLocked()
+	end := searchAt(length, b.readExactAtHeld, at, 1, false, func(chunk []byte) int {
+		return bytes.IndexByte(chunk, delim)
+	}) + 1
+	found = end > 0
+	if !found {
+		end = length
+	}
+	data = make([]byte, max(end-at, 0))
+	if err = b.readExactAtHeld(data, at); err != nil {
+		return 0, nil, false, err
+	}
+	b.offset += int64(len(data))
+	return at, data, found, nil
+}
+
+// ReadStringDelim is ReadBytes returning a string, the counterpart of
+// bufio.Reader.ReadString, named apart from ReadString which reads a fixed
+// number of bytes
+func (b *Buffer) ReadStringDelim(delim byte) (string, error) {
+	if b == nil {
+		panic("READSTRINGDELIM: buffer is nil")
+	}
+	data, err := b.ReadBytes(delim)
+	return string(data), err
+}
+
+// ReadLine reads the next line at the current offset without its trailing
+// "\n" or "\r\n", a final line without one is returned with a nil error and
+// io.EOF is returned only once nothing is left
+func (b *Buffer) ReadLine() ([]byte, error) {
+	if b == nil {
+		panic("READLINE: buffer is nil")
+	}
+	line, err := b.ReadBytes('\n')
+	if len(line) == 0 {
+		return nil, err
+	}
+	line = bytes.TrimSuffix(line, []byte{'\n'})
+	line = bytes.TrimSuffix(line, []byte{'\r'})
+	return line, nil
+}
@@ -0,0 +1,80 @@
+package crunchio
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestReadBytes(t *testing.T) {
+	b := NewBuffer("delim", []byte("ab,cd,ef"))
+	for _, want := range []string{"ab,", "cd,"} {
+		if data, err := b.ReadBytes(','); string(data) != want || err != nil {
+			t.Fatalf("ReadBytes = %q, %v, want %q", data, err, want)
+		}
+	}
+	if data, err := b.ReadBytes(','); string(data) != "ef" || err != io.EOF {
+		t.Fatalf("ReadBytes without a delimiter = %q, %v, want the rest and io.EOF", data, err)
+	}
+	if data, err := b.ReadBytes(','); data != nil || err != io.EOF {
+		t.Fatalf("ReadBytes at the end = %q, %v", data, err)
+	}
+
+	b.Seek(0, io.SeekStart)
+	if line, err := b.ReadStringDelim('d'); line != "ab,cd" || err != nil {
+		t.Fatalf("ReadStringDelim = %q, %v", line, err)
+	}
+
+	b.Close()
+	if _, err := b.ReadBytes(','); !errors.Is(err, ErrClosed) {
+		t.Fatalf("ReadBytes on a closed buffer = %v", err)
+	}
+}
+
+func TestReadBytesBit(t *testing.T) {
+	b := NewBuffer("delim", []byte("ab,cd,"))
+	if _, err := b.ReadBit(); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := b.ReadBytes(','); string(data) != "b," || err != nil {
+		t.Fatalf("ReadBytes mid-byte = %q, %v, want %q", data, err, "b,")
+	}
+	if b.TellBit() != 24 {
+		t.Fatalf("TellBit = %d, want 24", b.TellBit())
+	}
+}
+
+func TestReadBytesSlice(t *testing.T) {
+	b := NewBuffer("delim", []byte("ab,cd,ef"))
+	s := b.Slice(3, 3)
+	if data, err := s.ReadBytes(','); string(data) != "cd," || err != nil {
+		t.Fatalf("ReadBytes on a slice = %q, %v", data, err)
+	}
+	if data, err := s.ReadBytes(','); data != nil || err != io.EOF {
+		t.Fatalf("ReadBytes past the window = %q, %v", data, err)
+	}
+
+	s = b.Slice(0, 4)
+	if data, err := s.ReadBytes('e'); string(data) != "ab,c" || err != io.EOF {
+		t.Fatalf("ReadBytes stopping at the window = %q, %v", data, err)
+	}
+}
+
+func TestReadLine(t *testing.T) {
+	b := NewBuffer("delim", []byte("one\r\ntwo\n\nthree"))
+	var lines []string
+	for {
+		line, err := b.ReadLine()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, string(line))
+	}
+	if want := []string{"one", "two", "", "three"}; !slices.Equal(lines, want) {
+		t.Fatalf("ReadLine = %q, want %q", lines, want)
+	}
+}
@@ -0,0 +1,118 @@
+package crunchio
+
+import (
+	"fmt"
+)
+
+// OpKind identifies the kind of a delta operation
+type OpKind int
+
+const (
+	// OpCopy copies Length bytes from Offset in the source buffer
+	OpCopy OpKind = iota
+	// OpInsert inserts Data verbatim
+	OpInsert
+)
+
+// Op is a single delta operation produced by Diff and applied by Patch
+type Op struct {
+	Kind   OpKind
+	Offset int64
+	Length int64
+	Data   []byte
+}
+
+// diffBlockSize is the length of the blocks matched between the source and
+// target, shorter runs of common bytes are inserted instead of copied
+const diffBlockSize = 8
+
+// Diff computes the copy and insert operations that turn the contents of b
+// into the contents of other
+func (b *Buffer) Diff(other *Buffer) ([]Op, error) {
+	if b == nil {
+		panic("DIFF: buffer is nil")
+	}
+	if other == nil {
+		return nil, fmt.Errorf("buffer: diff: other buffer is nil")
+	}
+	if b.Closed() || other.Closed() {
+		return nil, fmt.Errorf("buffer: diff: %w", ErrClosed)
+	}
+	source := append([]byte{}, b.Bytes()...)
+	target := append([]byte{}, other.Bytes()...)
+	return diffBytes(source, target), nil
+}
+
+func diffBytes(source, target []byte) (ops []Op) {
+	index := make(map[string]int)
+	for i := 0; i+diffBlockSize <= len(source); i++ {
+		if _, ok := index[string(source[i:i+diffBlockSize])]; !ok {
+			index[string(source[i:i+diffBlockSize])] = i
+		}
+	}
+
+	var pending []byte
+	flush := func() {
+		if len(pending) > 0 {
+			ops = append(ops, Op{Kind: OpInsert, Data: pending})
+			pending = nil
+		}
+	}
+	for i := 0; i < len(target); {
+		if i+diffBlockSize <= len(target) {
+			if at, ok := index[string(target[i:i+diffBlockSize])]; ok {
+				n := diffBlockSize
+				for at+n < len(source) && i+n < len(target) && source[at+n] == target[i+n] {
+					n++
+				}
+				flush()
+				if last := len(ops) - 1; last >= 0 && ops[last].Kind == OpCopy && ops[last].Offset+ops[last].Length == int64(at) {
+					ops[last].Length += int64(n)
+				} else {
+					ops = append(ops, Op{Kind: OpCopy, Offset: int64(at), Length: int64(n)})
+				}
+				i += n
+				continue
+			}
+		}
+		pending = append(pending, target[i])
+		i++
+	}
+	flush()
+	return
+}
+
+// Patch applies ops produced by Diff to the contents of b, returning the
+// result as a new buffer and leaving b unchanged
+func (b *Buffer) Patch(ops []Op) (*Buffer, error) {
+	if b == nil {
+		panic("PATCH: buffer is nil")
+	}
+	if b.Closed() {
+		return nil, fmt.Errorf("buffer: patch: %w", ErrClosed)
+	}
+	source := b.Bytes()
+	size := int64(0)
+	for i, op := range ops {
+		switch op.Kind {
+		case OpCopy:
+			if op.Offset < 0 || op.Length < 0 || op.Offset+op.Length > int64(len(source)) {
+				return nil, fmt.Errorf("buffer: patch: op %d copies [%d, %d) outside of %d bytes", i, op.Offset, op.Offset+op.Length, len(source))
+			}
+			size += op.Length
+		case OpInsert:
+			size += int64(len(op.Data))
+		default:
+			return nil, fmt.Errorf("buffer: patch: op %d has unknown kind %d", i, op.Kind)
+		}
+	}
+	data := make([]byte, 0, size)
+	for _, op := range ops {
+		if op.Kind == OpCopy {
+			data = append(data, source[op.Offset:op.Offset+op.Length]...)
+		} else {
+			data = append(data, op.Data...)
+		}
+	}
+	return NewBuffer(b.name, data), nil
+}
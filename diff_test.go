@@ -0,0 +1,70 @@
+package crunchio
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDiffPatch(t *testing.T) {
+	const fox = "the quick brown fox "
+	for _, tt := range []struct {
+		name           string
+		source, target string
+	}{
+		{"equal", strings.Repeat(fox, 4), strings.Repeat(fox, 4)},
+		{"edited", strings.Repeat(fox, 10), "XX" + strings.Repeat(fox, 5) + "jumps" + strings.Repeat(fox, 3)},
+		{"empty source", "", fox},
+		{"empty target", fox, ""},
+		{"short", "abc", "abd"},
+	} {
+		source := NewBuffer("source", []byte(tt.source))
+		target := NewBuffer("target", []byte(tt.target))
+		ops, err := source.Diff(target)
+		if err != nil {
+			t.Fatalf("%s: Diff = %v", tt.name, err)
+		}
+		patched, err := source.Patch(ops)
+		if err != nil {
+			t.Fatalf("%s: Patch = %v", tt.name, err)
+		}
+		if got := patched.String(); got != tt.target {
+			t.Fatalf("%s: Patch produced %q, want %q", tt.name, got, tt.target)
+		}
+		if source.String() != tt.source {
+			t.Fatalf("%s: Patch changed the source to %q", tt.name, source.String())
+		}
+	}
+}
+
+func TestDiffCopiesCommonBytes(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 64)
+	source := NewBuffer("source", data)
+	target := NewBuffer("target", append(append([]byte("new"), data...), "tail"...))
+	ops, err := source.Diff(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inserted := 0
+	for _, op := range ops {
+		if op.Kind == OpInsert {
+			inserted += len(op.Data)
+		}
+	}
+	if inserted != len("new")+len("tail") {
+		t.Fatalf("Diff inserted %d bytes in %d ops, want only the 7 new bytes", inserted, len(ops))
+	}
+}
+
+func TestPatchOutOfRange(t *testing.T) {
+	source := NewBuffer("source", []byte("abc"))
+	for _, ops := range [][]Op{
+		{{Kind: OpCopy, Offset: 2, Length: 2}},
+		{{Kind: OpCopy, Offset: -1, Length: 1}},
+		{{Kind: OpKind(9)}},
+	} {
+		if _, err := source.Patch(ops); err == nil {
+			t.Fatalf("Patch(%+v) succeeded", ops)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package crunchio
+
+import (
+	"fmt"
+	"io"
+)
+
+// NewFileBuffer returns a buffer whose reads, writes and seeks go to f rather
+// than to an in-memory crunch buffer
+//
+// Operations that need a crunch buffer, such as Buffer and Shrink, report that
+// it is missing. Bytes reads the whole file into memory on every call and is
+// expensive for large files.
+func NewFileBuffer(name string, f io.ReadWriteSeeker) *Buffer {
+	if f == nil {
+		panic("NEWFILEBUFFER: file is nil")
+	}
+	b := new(Buffer)
+	b.file = f
+	if size, err := f.Seek(0, io.SeekEnd); err == nil {
+		b.length = size
+	}
+	b.SetName(name)
+	return b
+}
+
+// Sync flushes a file backing to stable storage when it supports Sync, as
+// *os.File does, and does nothing for an in-memory crunch backing
+func (b *Buffer) Sync() error {
+	if b == nil {
+		panic("SYNC: buffer is nil")
+	}
+	root := b.root()
+	root.Lock()
+	defer root.Unlock()
+	return root.syncLocked()
+}
+
+// syncLocked is Sync for a caller already holding the root's lock
+func (b *Buffer) syncLocked() error {
+	if f, ok := b.file.(interface{ Sync() error }); ok {
+		if err := f.Sync(); err != nil {
+			return fmt.Errorf("buffer: sync: %w", err)
+		}
+	}
+	return nil
+}
+
+// readFileAt reads from the backing file at offset, stopping at the logical
+// end of the buffer, the caller must hold the lock
+func (b *Buffer) readFileAt(dst []byte, offset int64) (read int, err error) {
+	toRead := b.length - offset
+	if int(toRead) > len(dst) {
+		toRead = int64(len(dst))
+	}
+	if toRead <= 0 {
+		if b.stream {
+			return 0, nil
+		}
+		return 0, io.EOF
+	}
+	dst = dst[:toRead]
+	if ra, ok := b.file.(io.ReaderAt); ok {
+		read, err = ra.ReadAt(dst, offset)
+	} else if _, err = b.file.Seek(offset, io.SeekStart); err == nil {
+		read, err = io.ReadFull(b.file, dst)
+	}
+	if err == io.EOF && read == len(dst) {
+		err = nil
+	}
+	if err != nil {
+		err = fmt.Errorf("buffer: read: %w", err)
+	}
+	return
+}
+
+// writeFileAt writes to the backing file at offset, extending the logical end
+// of the buffer as needed, the caller must hold the lock
+func (b *Buffer) writeFileAt(src []byte, offset int64) (wrote int, err error) {
+	if gap := offset - b.length; gap > 0 {
+		if _, err = b.writeFileAt(make([]byte, gap), b.length); err != nil {
+			return
+		}
+	}
+	if wa, ok := b.file.(io.WriterAt); ok {
+		wrote, err = wa.WriteAt(src, offset)
+	} else if _, err = b.file.Seek(offset, io.SeekStart); err == nil {
+		wrote, err = b.file.Write(src)
+	}
+	if end := offset + int64(wrote); end > b.length {
+		b.length = end
+	}
+	b.writes.Add(1)
+	if err != nil {
+		err = fmt.Errorf("buffer: write: %w", err)
+	}
+	return
+}
@@ -0,0 +1,120 @@
+package crunchio
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTempFileBuffer(t *testing.T, contents string) (*Buffer, *os.File) {
+	t.Helper()
+	f, err := os.Create(filepath.Join(t.TempDir(), "backing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	if _, err := f.WriteString(contents); err != nil {
+		t.Fatal(err)
+	}
+	return NewFileBuffer("file", f), f
+}
+
+func TestFileBufferSeekReadWrite(t *testing.T) {
+	b, f := newTempFileBuffer(t, "hello world")
+	if b.Size() != 11 {
+		t.Fatalf("Size = %d, want 11", b.Size())
+	}
+	b.Seek(6, io.SeekStart)
+	p := make([]byte, 5)
+	if n, err := b.Read(p); n != 5 || err != nil || string(p) != "world" {
+		t.Fatalf("Read = %d, %v, %q", n, err, p)
+	}
+	b.Seek(0, io.SeekStart)
+	if _, err := b.Write([]byte("HELLO")); err != nil {
+		t.Fatal(err)
+	}
+	b.Seek(0, io.SeekEnd)
+	b.Write([]byte("!"))
+	b.Seek(0, io.SeekStart)
+	data, err := io.ReadAll(b)
+	if err != nil || string(data) != "HELLO world!" {
+		t.Fatalf("ReadAll = %q, %v", data, err)
+	}
+	onDisk, err := os.ReadFile(f.Name())
+	if err != nil || string(onDisk) != "HELLO world!" {
+		t.Fatalf("file holds %q, %v", onDisk, err)
+	}
+}
+func TestFileBufferReference(t *testing.T) {
+	b, _ := newTempFileBuffer(t, "\x1f\x8b\x08\x00rest")
+	ref := b.Reference()
+	p := make([]byte, 4)
+	if n, err := ref.ReadAt(p, 4); n != 4 || err != nil || string(p) != "rest" {
+		t.Fatalf("reference ReadAt = %d, %v, %q", n, err, p)
+	}
+	if got := ref.Sniff(); got != "gzip" {
+		t.Fatalf("reference Sniff = %q, want gzip", got)
+	}
+	if got := string(ref.Bytes()); got != "\x1f\x8b\x08\x00rest" {
+		t.Fatalf("reference Bytes = %q", got)
+	}
+}
+
+func TestFileBufferReferenceBytesConcurrentRead(t *testing.T) {
+	b, _ := newTempFileBuffer(t, "0123456789")
+	ref := b.Reference()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p := make([]byte, 10)
+		for i := 0; i < 200; i++ {
+			b.Seek(0, io.SeekStart)
+			b.Read(p)
+		}
+	}()
+	for i := 0; i < 200; i++ {
+		if got := string(ref.Bytes()); got != "0123456789" {
+			t.Errorf("reference Bytes = %q", got)
+			break
+		}
+	}
+	<-done
+}
+
+// syncCounter counts the Sync calls made on the file it wraps
+type syncCounter struct {
+	*os.File
+	syncs int
+}
+
+func (s *syncCounter) Sync() error {
+	s.syncs++
+	return s.File.Sync()
+}
+
+func TestFileBufferSync(t *testing.T) {
+	_, f := newTempFileBuffer(t, "")
+	file := &syncCounter{File: f}
+	b := NewFileBuffer("file", file)
+	b.Write([]byte("persisted"))
+	if err := b.Reference().Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if file.syncs != 1 {
+		t.Fatalf("Sync through a reference synced the file %d times", file.syncs)
+	}
+	data, err := os.ReadFile(f.Name())
+	if err != nil || string(data) != "persisted" {
+		t.Fatalf("file holds %q, %v after Sync", data, err)
+	}
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if file.syncs != 2 {
+		t.Fatalf("Close synced the file %d times in total, want 2", file.syncs)
+	}
+	if err := NewBuffer("memory", []byte("x")).Sync(); err != nil {
+		t.Fatalf("Sync on an in-memory buffer = %v", err)
+	}
+}
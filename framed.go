@@ -0,0 +1,99 @@
+package crunchio
+
+import (
+	"fmt"
+	"io"
+)
+
+// WriteAbstractFramed writes a []string or [][]byte so it can be read back by
+// ReadAbstractFramed: a uint32 element count followed by each element as a
+// uint32 length and its bytes, all in the buffer's byte order
+func (b *Buffer) WriteAbstractFramed(data any) (wrote int, err error) {
+	if b == nil {
+		panic("WRITEABSTRACTFRAMED: buffer is nil")
+	}
+	var elements [][]byte
+	switch data := data.(type) {
+	case []string:
+		elements = make([][]byte, len(data))
+		for i := range data {
+			elements[i] = []byte(data[i])
+		}
+	case [][]byte:
+		elements = data
+	default:
+		return 0, fmt.Errorf("buffer: Unsupported type for framed abstract write: %T", data)
+	}
+
+	order := b.byteOrder()
+	size := 4
+	for i := range elements {
+		size += 4 + len(elements[i])
+	}
+	frame := make([]byte, size)
+	order.PutUint32(frame, uint32(len(elements)))
+	at := 4
+	for i := range elements {
+		order.PutUint32(frame[at:], uint32(len(elements[i])))
+		at += 4 + copy(frame[at+4:], elements[i])
+	}
+	return b.Write(frame)
+}
+
+// ReadAbstractFramed reads a slice written by WriteAbstractFramed into a
+// *[]string or *[][]byte, restoring the offset on failure
+func (b *Buffer) ReadAbstractFramed(dst any) (err error) {
+	if b == nil {
+		panic("READABSTRACTFRAMED: buffer is nil")
+	}
+	switch dst.(type) {
+	case *[]string, *[][]byte:
+	default:
+		return fmt.Errorf("buffer: Unsupported type for framed abstract read: %T", dst)
+	}
+
+	start := b.Tell()
+	defer func() {
+		if err != nil {
+			b.Seek(start, io.SeekStart)
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+		}
+	}()
+	order := b.byteOrder()
+	var prefix [4]byte
+	if err = b.readExact(prefix[:]); err != nil {
+		return
+	}
+	count := order.Uint32(prefix[:])
+	if int64(count)*4 > b.Remaining() {
+		return fmt.Errorf("buffer: framed abstract read: count %d exceeds the remaining bytes", count)
+	}
+	elements := make([][]byte, count)
+	for i := range elements {
+		if err = b.readExact(prefix[:]); err != nil {
+			return
+		}
+		length := order.Uint32(prefix[:])
+		if int64(length) > b.Remaining() {
+			return io.ErrUnexpectedEOF
+		}
+		elements[i] = make([]byte, length)
+		if err = b.readExact(elements[i]); err != nil {
+			return
+		}
+	}
+
+	switch dst := dst.(type) {
+	case *[]string:
+		strings := make([]string, count)
+		for i := range elements {
+			strings[i] = string(elements[i])
+		}
+		*dst = strings
+	case *[][]byte:
+		*dst = elements
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package crunchio
+
+import (
+	"bytes"
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestAbstractFramedStrings(t *testing.T) {
+	in := []string{"a", "", "héllo", "日本語", ""}
+	b := NewBuffer("framed")
+	if _, err := b.WriteAbstractFramed(in); err != nil {
+		t.Fatal(err)
+	}
+	b.Seek(0, io.SeekStart)
+	var out []string
+	if err := b.ReadAbstractFramed(&out); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(out, in) {
+		t.Fatalf("ReadAbstractFramed = %q, want %q", out, in)
+	}
+	if err := b.ReadAbstractFramed(&out); err != io.ErrUnexpectedEOF {
+		t.Fatalf("ReadAbstractFramed at the end = %v, want io.ErrUnexpectedEOF", err)
+	}
+}
+
+func TestAbstractFramedBytes(t *testing.T) {
+	in := [][]byte{{1, 2, 3}, {}, {0}}
+	b := NewBuffer("framed")
+	b.WriteAbstractFramed(in)
+	b.Seek(0, io.SeekStart)
+	var out [][]byte
+	if err := b.ReadAbstractFramed(&out); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.EqualFunc(out, in, bytes.Equal) {
+		t.Fatalf("ReadAbstractFramed = %v, want %v", out, in)
+	}
+}
+
+func TestAbstractFramedTruncated(t *testing.T) {
+	b := NewBuffer("framed")
+	b.WriteAbstractFramed([]string{"abc", "def"})
+	b.Truncate(b.Size() - 1)
+	b.Seek(0, io.SeekStart)
+	var out []string
+	if err := b.ReadAbstractFramed(&out); err != io.ErrUnexpectedEOF {
+		t.Fatalf("ReadAbstractFramed of a truncated frame = %v, want io.ErrUnexpectedEOF", err)
+	}
+	if b.Tell() != 0 || out != nil {
+		t.Fatalf("failed read left the offset at %d and read %q", b.Tell(), out)
+	}
+}
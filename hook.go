@@ -0,0 +1,51 @@
+package crunchio
+
+// SetReadHook installs a hook called after every successful read with the
+// offset read from and a copy of the bytes read, or removes it when nil
+//
+// Hooks run outside the buffer's lock, so they may safely use the buffer.
+func (b *Buffer) SetReadHook(hook func(offset int64, data []byte)) {
+	if b == nil {
+		panic("SETREADHOOK: buffer is nil")
+	}
+	b.Lock()
+	defer b.Unlock()
+	b.readHook = hook
+}
+
+// SetWriteHook installs a hook called after every successful write with the
+// offset written to and a copy of the bytes written, or removes it when nil
+//
+// Hooks run outside the buffer's lock, so they may safely use the buffer.
+func (b *Buffer) SetWriteHook(hook func(offset int64, data []byte)) {
+	if b == nil {
+		panic("SETWRITEHOOK: buffer is nil")
+	}
+	b.Lock()
+	defer b.Unlock()
+	b.writeHook = hook
+}
+
+func (b *Buffer) afterRead(offset int64, data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	b.RLock()
+	hook := b.readHook
+	b.RUnlock()
+	if hook != nil {
+		hook(offset, append([]byte{}, data...))
+	}
+}
+
+func (b *Buffer) afterWrite(offset int64, data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	b.RLock()
+	hook := b.writeHook
+	b.RUnlock()
+	if hook != nil {
+		hook(offset, append([]byte{}, data...))
+	}
+}
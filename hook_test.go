@@ -0,0 +1,54 @@
+package crunchio
+
+import (
+	"io"
+	"testing"
+)
+
+// hookCall is a single call made to a read or write hook
+type hookCall struct {
+	offset int64
+	data   string
+}
+
+func TestHooks(t *testing.T) {
+	b := NewBuffer("hooks")
+	var reads, writes []hookCall
+	b.SetWriteHook(func(offset int64, data []byte) {
+		writes = append(writes, hookCall{offset, string(data)})
+		b.Size()
+	})
+	b.SetReadHook(func(offset int64, data []byte) {
+		reads = append(reads, hookCall{offset, string(data)})
+	})
+	b.Write([]byte("hello"))
+	b.WriteAt([]byte("!"), 8)
+	b.Seek(1, io.SeekStart)
+	b.Read(make([]byte, 3))
+	b.ReadAt(make([]byte, 2), 7)
+	b.Seek(20, io.SeekStart)
+	b.Read(make([]byte, 3))
+
+	wantWrites := []hookCall{{0, "hello"}, {8, "!"}}
+	wantReads := []hookCall{{1, "ell"}, {7, "\x00!"}}
+	if len(writes) != len(wantWrites) || writes[0] != wantWrites[0] || writes[1] != wantWrites[1] {
+		t.Fatalf("write hook saw %v, want %v", writes, wantWrites)
+	}
+	if len(reads) != len(wantReads) || reads[0] != wantReads[0] || reads[1] != wantReads[1] {
+		t.Fatalf("read hook saw %v, want %v", reads, wantReads)
+	}
+}
+
+func TestHookGetsCopy(t *testing.T) {
+	b := NewBuffer("hooks")
+	b.SetWriteHook(func(offset int64, data []byte) {
+		data[0] = 'X'
+	})
+	src := []byte("abc")
+	b.Write(src)
+	if string(src) != "abc" || b.String() != "abc" {
+		t.Fatalf("hook changed the written bytes to %q, %q", src, b.String())
+	}
+	b.SetWriteHook(nil)
+	b.Write([]byte("d"))
+}
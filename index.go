@@ -0,0 +1,79 @@
+package crunchio
+
+import (
+	"bytes"
+)
+
+// searchChunk is the size of the chunks searches read at a time, each read
+// overlapping the next by one byte less than the pattern
+const searchChunk = 4096
+
+// Index returns the absolute offset of the first occurrence of sep at or after
+// from, or -1 if there is none
+func (b *Buffer) Index(sep []byte, from int64) int64 {
+	if b == nil {
+		panic("INDEX: buffer is nil")
+	}
+	return b.search(from, len(sep), false, func(chunk []byte) int {
+		return bytes.Index(chunk, sep)
+	})
+}
+
+// IndexByte returns the absolute offset of the first c at or after from, or -1
+// if there is none
+func (b *Buffer) IndexByte(c byte, from int64) int64 {
+	if b == nil {
+		panic("INDEXBYTE: buffer is nil")
+	}
+	return b.search(from, 1, false, func(chunk []byte) int {
+		return bytes.IndexByte(chunk, c)
+	})
+}
+
+// LastIndex returns the absolute offset of the last occurrence of sep at or
+// after from, or -1 if there is none
+func (b *Buffer) LastIndex(sep []byte, from int64) int64 {
+	if b == nil {
+		panic("LASTINDEX: buffer is nil")
+	}
+	return b.search(from, len(sep), true, func(chunk []byte) int {
+		return bytes.LastIndex(chunk, sep)
+	})
+}
+
+// search runs find over the buffer from from onwards a chunk at a time, find
+// returning the index in the chunk of a match width bytes long or -1, and
+// returns the absolute offset of the first match, or the last when last is set
+func (b *Buffer) search(from int64, width int, last bool, find func(chunk []byte) int) int64 {
+	return searchAt(b.Size(), b.readExactAt, from, width, last, find)
+}
+
+// searchAt is search over length bytes read through readAt, letting a caller
+// that already holds the lock search with readExactAtHeld
+func searchAt(length int64, readAt func(dst []byte, offset int64) error, from int64, width int, last bool, find func(chunk []byte) int) int64 {
+	from = max(from, 0)
+	if from+int64(width) > length {
+		return -1
+	}
+	if width == 0 {
+		if last {
+			return length
+		}
+		return from
+	}
+	found := int64(-1)
+	chunk := make([]byte, searchChunk+width-1)
+	for at := from; at+int64(width) <= length; at += searchChunk {
+		window := chunk[:min(int64(len(chunk)), length-at)]
+		if readAt(window, at) != nil {
+			break
+		}
+		if i := find(window); i >= 0 {
+			found = at + int64(i)
+			if !last {
+				break
+			}
+		}
+	}
+	return found
+}
@@ -0,0 +1,67 @@
+package crunchio
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestIndex(t *testing.T) {
+	b := NewBuffer("index", []byte("abcabcab"))
+	for _, tt := range []struct {
+		name string
+		got  int64
+		want int64
+	}{
+		{"Index found", b.Index([]byte("ca"), 0), 2},
+		{"Index from a later match", b.Index([]byte("ca"), 3), 5},
+		{"Index not found", b.Index([]byte("cc"), 0), -1},
+		{"Index negative from", b.Index([]byte("ab"), -4), 0},
+		{"Index from past the end", b.Index([]byte("ab"), 9), -1},
+		{"Index empty sep", b.Index(nil, 3), 3},
+		{"IndexByte found", b.IndexByte('c', 0), 2},
+		{"IndexByte from a later match", b.IndexByte('c', 3), 5},
+		{"IndexByte not found", b.IndexByte('z', 0), -1},
+		{"IndexByte from past the end", b.IndexByte('a', 8), -1},
+		{"LastIndex found", b.LastIndex([]byte("ab"), 0), 6},
+		{"LastIndex not found", b.LastIndex([]byte("ba"), 0), -1},
+		{"LastIndex from past the last match", b.LastIndex([]byte("bc"), 5), -1},
+		{"LastIndex from past the end", b.LastIndex([]byte("ab"), 20), -1},
+	} {
+		if tt.got != tt.want {
+			t.Fatalf("%s = %d, want %d", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestIndexChunks(t *testing.T) {
+	data := bytes.Repeat([]byte{'.'}, 3*searchChunk)
+	copy(data[searchChunk-1:], "needle")
+	copy(data[2*searchChunk+7:], "needle")
+	b := NewBuffer("index", data)
+	if at := b.Index([]byte("needle"), 0); at != searchChunk-1 {
+		t.Fatalf("Index across a chunk boundary = %d, want %d", at, searchChunk-1)
+	}
+	if at := b.LastIndex([]byte("needle"), 0); at != 2*searchChunk+7 {
+		t.Fatalf("LastIndex = %d, want %d", at, 2*searchChunk+7)
+	}
+}
+
+func TestIndexSlice(t *testing.T) {
+	b := NewBuffer("index", []byte("xxabcxxabc"))
+	s := b.Slice(1, 7)
+	if at := s.Index([]byte("abc"), 0); at != 1 {
+		t.Fatalf("Index in a slice = %d, want 1", at)
+	}
+	if at := s.IndexByte('a', 2); at != 6 {
+		t.Fatalf("IndexByte in a slice = %d, want 6", at)
+	}
+	if at := s.Index([]byte("abc"), 2); at != -1 {
+		t.Fatalf("Index of a match cut off by the window = %d, want -1", at)
+	}
+	if at := s.LastIndex([]byte("bc"), 0); at != 2 {
+		t.Fatalf("LastIndex in a slice = %d, want 2", at)
+	}
+	if at := s.IndexByte('x', 7); at != -1 {
+		t.Fatalf("IndexByte from the end of the window = %d, want -1", at)
+	}
+}
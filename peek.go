@@ -0,0 +1,44 @@
+package crunchio
+
+import (
+	"fmt"
+	"io"
+)
+
+// Peek returns the next n bytes at the current offset without advancing it,
+// returning the bytes that remain along with io.EOF if fewer than n do
+func (b *Buffer) Peek(n int) ([]byte, error) {
+	if b == nil {
+		panic("PEEK: buffer is nil")
+	}
+	b.Lock()
+	at := b.offset
+	if b.bit != 0 {
+		at++
+	}
+	b.Unlock()
+	return b.PeekAt(at, n)
+}
+
+// PeekAt returns the n bytes at off without reading or moving the offset, so
+// the read hook isn't called, returning the bytes that remain along with io.EOF
+// if fewer than n do
+func (b *Buffer) PeekAt(off int64, n int) ([]byte, error) {
+	if b == nil {
+		panic("PEEKAT: buffer is nil")
+	}
+	if off < 0 {
+		return nil, fmt.Errorf("buffer: peek: negative offset %d", off)
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("buffer: peek: negative length %d", n)
+	}
+	data := make([]byte, min(int64(n), max(b.Size()-off, 0)))
+	if err := b.readExactAt(data, off); err != nil {
+		return nil, err
+	}
+	if len(data) < n {
+		return data, io.EOF
+	}
+	return data, nil
+}
@@ -0,0 +1,43 @@
+package crunchio
+
+import (
+	"io"
+	"testing"
+)
+
+func TestPeek(t *testing.T) {
+	b := NewBuffer("peek", []byte("abcdef"))
+	hooked := false
+	b.SetReadHook(func(int64, []byte) { hooked = true })
+	b.Seek(2, io.SeekStart)
+	if data, err := b.Peek(3); string(data) != "cde" || err != nil {
+		t.Fatalf("Peek(3) = %q, %v", data, err)
+	}
+	if b.Tell() != 2 {
+		t.Fatalf("Peek moved the offset to %d", b.Tell())
+	}
+	if data, err := b.Peek(10); string(data) != "cdef" || err != io.EOF {
+		t.Fatalf("Peek past the end = %q, %v, want the rest and io.EOF", data, err)
+	}
+	if data, err := b.PeekAt(5, 1); string(data) != "f" || err != nil {
+		t.Fatalf("PeekAt(5, 1) = %q, %v", data, err)
+	}
+	if data, err := b.PeekAt(6, 1); len(data) != 0 || err != io.EOF {
+		t.Fatalf("PeekAt at the end = %q, %v", data, err)
+	}
+	if _, err := b.PeekAt(-1, 1); err == nil {
+		t.Fatal("PeekAt(-1, 1) succeeded")
+	}
+	if _, err := b.PeekAt(0, -1); err == nil {
+		t.Fatal("PeekAt(0, -1) succeeded")
+	}
+	if hooked {
+		t.Fatal("peeking called the read hook")
+	}
+
+	b.Seek(0, io.SeekStart)
+	b.ReadBit()
+	if data, err := b.Peek(1); string(data) != "b" || err != nil {
+		t.Fatalf("Peek mid-byte = %q, %v, want the next aligned byte", data, err)
+	}
+}
@@ -0,0 +1,99 @@
+package crunchio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrBadHeader is returned by Load when the saved header is corrupt
+var ErrBadHeader = errors.New("bad buffer header")
+
+const (
+	saveMagic   = "CRIO"
+	saveVersion = 1
+)
+
+// Save writes the buffer to w as a self-describing record: the magic "CRIO",
+// a version byte, the name as a uint16 length and bytes, a byte order flag
+// (0 little-endian, 1 big-endian), the length as a uint64 and the contents,
+// with all header integers in little-endian
+func (b *Buffer) Save(w io.Writer) error {
+	if b == nil {
+		panic("SAVE: buffer is nil")
+	}
+	b.Lock()
+	if b.Closed() {
+		b.Unlock()
+		return fmt.Errorf("buffer: save: %w", ErrClosed)
+	}
+	name := b.name
+	order := byte(0)
+	if b.byteOrder() == binary.BigEndian {
+		order = 1
+	}
+	data := b.snapshot()
+	b.Unlock()
+	if len(name) > 0xFFFF {
+		return fmt.Errorf("buffer: save: name is %d bytes long", len(name))
+	}
+
+	header := make([]byte, 0, len(saveMagic)+1+2+len(name)+1+8)
+	header = append(header, saveMagic...)
+	header = append(header, saveVersion)
+	header = binary.LittleEndian.AppendUint16(header, uint16(len(name)))
+	header = append(header, name...)
+	header = append(header, order)
+	header = binary.LittleEndian.AppendUint64(header, uint64(len(data)))
+	if _, err := w.Write(header); err != nil {
+		return fmt.Errorf("buffer: save: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("buffer: save: %w", err)
+	}
+	return nil
+}
+
+// Load reads a buffer written by Save from r
+func Load(r io.Reader) (*Buffer, error) {
+	var fixed [len(saveMagic) + 1 + 2]byte
+	if _, err := io.ReadFull(r, fixed[:]); err != nil {
+		return nil, fmt.Errorf("buffer: load: %w", err)
+	}
+	if string(fixed[:len(saveMagic)]) != saveMagic {
+		return nil, fmt.Errorf("buffer: load: bad magic %q: %w", fixed[:len(saveMagic)], ErrBadHeader)
+	}
+	if version := fixed[len(saveMagic)]; version != saveVersion {
+		return nil, fmt.Errorf("buffer: load: unsupported version %d: %w", version, ErrBadHeader)
+	}
+	name := make([]byte, binary.LittleEndian.Uint16(fixed[len(saveMagic)+1:]))
+	if _, err := io.ReadFull(r, name); err != nil {
+		return nil, fmt.Errorf("buffer: load: %w", err)
+	}
+	var trailer [1 + 8]byte
+	if _, err := io.ReadFull(r, trailer[:]); err != nil {
+		return nil, fmt.Errorf("buffer: load: %w", err)
+	}
+	if trailer[0] > 1 {
+		return nil, fmt.Errorf("buffer: load: bad byte order %d: %w", trailer[0], ErrBadHeader)
+	}
+	length := binary.LittleEndian.Uint64(trailer[1:])
+	if length > 1<<62 {
+		return nil, fmt.Errorf("buffer: load: bad length %d: %w", length, ErrBadHeader)
+	}
+
+	b := NewBuffer(string(name))
+	if trailer[0] == 1 {
+		b.SetByteOrder(binary.BigEndian)
+	}
+	copied, err := io.CopyN(b, r, int64(length))
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, fmt.Errorf("buffer: load: read %d of %d bytes: %w", copied, length, err)
+	}
+	b.offset = 0
+	return b, nil
+}
@@ -0,0 +1,63 @@
+package crunchio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestSaveLoad(t *testing.T) {
+	b := NewBuffer("saved", []byte("some \x00 contents"))
+	b.SetByteOrder(binary.BigEndian)
+	b.Seek(4, io.SeekStart)
+	var stream bytes.Buffer
+	if err := b.Save(&stream); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(stream.Bytes(), []byte("CRIO\x01")) {
+		t.Fatalf("Save wrote header % x", stream.Bytes()[:5])
+	}
+	loaded, err := Load(&stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.name != "saved" || loaded.String() != b.String() || loaded.Tell() != 0 {
+		t.Fatalf("Load = %q holding %q at %d", loaded.name, loaded.String(), loaded.Tell())
+	}
+	if loaded.byteOrder() != binary.BigEndian {
+		t.Fatal("Load lost the byte order")
+	}
+	if stream.Len() != 0 {
+		t.Fatalf("Load left %d bytes unread", stream.Len())
+	}
+}
+
+func TestLoadCorrupt(t *testing.T) {
+	var stream bytes.Buffer
+	NewBuffer("saved", []byte("contents")).Save(&stream)
+	saved := stream.Bytes()
+	for _, tt := range []struct {
+		name string
+		at   int
+		c    byte
+	}{
+		{"magic", 0, 'X'},
+		{"version", 4, 9},
+		{"byte order", 4 + 1 + 2 + len("saved"), 2},
+		{"length", len(saved) - len("contents") - 1, 0xFF},
+	} {
+		data := bytes.Clone(saved)
+		data[tt.at] = tt.c
+		if _, err := Load(bytes.NewReader(data)); !errors.Is(err, ErrBadHeader) {
+			t.Fatalf("Load with a corrupt %s = %v, want ErrBadHeader", tt.name, err)
+		}
+	}
+	if _, err := Load(bytes.NewReader(saved[:len(saved)-1])); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("Load of truncated contents = %v, want io.ErrUnexpectedEOF", err)
+	}
+	if _, err := Load(bytes.NewReader(saved[:3])); err == nil {
+		t.Fatal("Load of a truncated header succeeded")
+	}
+}
@@ -0,0 +1,105 @@
+package crunchio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// PrefixKind selects how the length of a prefixed string is encoded
+type PrefixKind int
+
+const (
+	// PrefixU8 encodes the length as a uint8
+	PrefixU8 PrefixKind = iota
+	// PrefixU16 encodes the length as a uint16 in the buffer's byte order
+	PrefixU16
+	// PrefixU32 encodes the length as a uint32 in the buffer's byte order
+	PrefixU32
+	// PrefixVarint encodes the length as an encoding/binary uvarint
+	PrefixVarint
+)
+
+// maxLen returns the longest length the prefix can encode
+func (p PrefixKind) maxLen() uint64 {
+	switch p {
+	case PrefixU8:
+		return math.MaxUint8
+	case PrefixU16:
+		return math.MaxUint16
+	case PrefixU32:
+		return math.MaxUint32
+	}
+	return math.MaxUint64
+}
+
+// WriteStringPrefixed writes the length of s followed by its bytes at the
+// current offset in a single write
+func (b *Buffer) WriteStringPrefixed(s string, prefix PrefixKind) (int, error) {
+	if b == nil {
+		panic("WRITESTRINGPREFIXED: buffer is nil")
+	}
+	if prefix < PrefixU8 || prefix > PrefixVarint {
+		return 0, fmt.Errorf("buffer: writestringprefixed: unknown prefix kind %d", prefix)
+	}
+	if uint64(len(s)) > prefix.maxLen() {
+		return 0, fmt.Errorf("buffer: writestringprefixed: length %d does not fit the prefix", len(s))
+	}
+	frame := make([]byte, binary.MaxVarintLen64, binary.MaxVarintLen64+len(s))
+	order := b.byteOrder()
+	switch prefix {
+	case PrefixU8:
+		frame[0] = byte(len(s))
+		frame = frame[:1]
+	case PrefixU16:
+		order.PutUint16(frame, uint16(len(s)))
+		frame = frame[:2]
+	case PrefixU32:
+		order.PutUint32(frame, uint32(len(s)))
+		frame = frame[:4]
+	case PrefixVarint:
+		frame = frame[:binary.PutUvarint(frame, uint64(len(s)))]
+	}
+	return b.Write(append(frame, s...))
+}
+
+// ReadStringPrefixed reads a string written by WriteStringPrefixed with the
+// same prefix, restoring the cursor on failure
+func (b *Buffer) ReadStringPrefixed(prefix PrefixKind) (s string, err error) {
+	if b == nil {
+		panic("READSTRINGPREFIXED: buffer is nil")
+	}
+	start := b.TellBit()
+	var n uint64
+	switch prefix {
+	case PrefixU8:
+		var v uint8
+		v, err = b.ReadU8()
+		n = uint64(v)
+	case PrefixU16:
+		var v uint16
+		v, err = b.ReadU16()
+		n = uint64(v)
+	case PrefixU32:
+		var v uint32
+		v, err = b.ReadU32()
+		n = uint64(v)
+	case PrefixVarint:
+		n, err = b.ReadUvarint()
+	default:
+		return "", fmt.Errorf("buffer: readstringprefixed: unknown prefix kind %d", prefix)
+	}
+	if err != nil {
+		b.SeekBit(start)
+		return "", err
+	}
+	if n > uint64(b.Remaining()) {
+		b.SeekBit(start)
+		return "", io.ErrUnexpectedEOF
+	}
+	if s, err = b.ReadString(int(n)); err != nil {
+		b.SeekBit(start)
+	}
+	return
+}
@@ -0,0 +1,71 @@
+package crunchio
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestStringPrefixed(t *testing.T) {
+	long := strings.Repeat("x", 300)
+	for _, test := range []struct {
+		prefix PrefixKind
+		s      string
+		header []byte
+	}{
+		{PrefixU8, "hi", []byte{2}},
+		{PrefixU8, "", []byte{0}},
+		{PrefixU16, "hi", []byte{0, 2}},
+		{PrefixU32, "hi", []byte{0, 0, 0, 2}},
+		{PrefixVarint, "hi", []byte{2}},
+		{PrefixVarint, long, []byte{0xAC, 0x02}},
+		{PrefixU16, long, []byte{0x01, 0x2C}},
+	} {
+		b := NewBuffer("prefixed")
+		b.SetByteOrder(binary.BigEndian)
+		want := append(slices.Clone(test.header), test.s...)
+		if n, err := b.WriteStringPrefixed(test.s, test.prefix); n != len(want) || err != nil {
+			t.Fatalf("prefix %d: WriteStringPrefixed = %d, %v, want %d", test.prefix, n, err, len(want))
+		}
+		if got := b.Bytes(); !slices.Equal(got, want) {
+			t.Fatalf("prefix %d: wrote % x, want % x", test.prefix, got, want)
+		}
+		b.Seek(0, io.SeekStart)
+		if s, err := b.ReadStringPrefixed(test.prefix); s != test.s || err != nil || b.Tell() != int64(len(want)) {
+			t.Fatalf("prefix %d: ReadStringPrefixed = %d bytes, %v at %d", test.prefix, len(s), err, b.Tell())
+		}
+	}
+}
+
+func TestStringPrefixedErrors(t *testing.T) {
+	b := NewBuffer("prefixed")
+	if _, err := b.WriteStringPrefixed(strings.Repeat("x", 256), PrefixU8); err == nil || b.Size() != 0 {
+		t.Fatalf("WriteStringPrefixed of 256 bytes with a u8 prefix = %v, Size %d", err, b.Size())
+	}
+	if _, err := b.WriteStringPrefixed("x", PrefixKind(9)); err == nil {
+		t.Fatal("WriteStringPrefixed with an unknown prefix succeeded")
+	}
+	if _, err := b.ReadStringPrefixed(PrefixKind(-1)); err == nil {
+		t.Fatal("ReadStringPrefixed with an unknown prefix succeeded")
+	}
+
+	b = NewBuffer("prefixed", []byte{'>', 5, 'a', 'b'})
+	b.Seek(1, io.SeekStart)
+	if _, err := b.ReadStringPrefixed(PrefixU8); !errors.Is(err, io.ErrUnexpectedEOF) || b.Tell() != 1 {
+		t.Fatalf("ReadStringPrefixed of a truncated string = %v at %d, want io.ErrUnexpectedEOF at 1", err, b.Tell())
+	}
+	if _, err := b.ReadStringPrefixed(PrefixU32); err == nil {
+		t.Fatal("ReadStringPrefixed of a truncated u32 prefix succeeded")
+	}
+
+	b.Seek(0, io.SeekStart)
+	b.ReadBits(3)
+	for _, prefix := range []PrefixKind{PrefixU8, PrefixU32} {
+		if _, err := b.ReadStringPrefixed(prefix); err == nil || b.TellBit() != 3 {
+			t.Fatalf("failed ReadStringPrefixed(%d) mid-byte = %v at bit %d, want the cursor back at bit 3", prefix, err, b.TellBit())
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package crunchio
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket holding up to one second of bytes, shared by
+// a buffer and every reference taken from it
+type rateLimiter struct {
+	sync.Mutex
+	rate   int64
+	tokens float64
+	last   time.Time
+}
+
+// SetRateLimit caps the combined throughput of Read and Write on the buffer
+// and every reference sharing its bytes to bytesPerSec, blocking them until
+// enough budget is available or their deadline passes, a zero or negative
+// rate disables limiting
+func (b *Buffer) SetRateLimit(bytesPerSec int64) {
+	if b == nil {
+		panic("SETRATELIMIT: buffer is nil")
+	}
+	root := b.root()
+	if bytesPerSec <= 0 {
+		root.limiter.Store(nil)
+		return
+	}
+	root.limiter.Store(&rateLimiter{rate: bytesPerSec, tokens: float64(bytesPerSec), last: time.Now()})
+}
+
+// throttle takes n bytes of budget from the rate limiter of the buffer that
+// owns the bytes, sleeping until it is available or the deadline in Unix
+// nanoseconds passes, returning the limiter to refund unused budget to
+func (b *Buffer) throttle(n int, deadline int64) (*rateLimiter, error) {
+	l := b.root().limiter.Load()
+	if l == nil || n <= 0 {
+		return nil, nil
+	}
+	l.Lock()
+	now := time.Now()
+	l.tokens = min(l.tokens+now.Sub(l.last).Seconds()*float64(l.rate), float64(l.rate))
+	l.last = now
+	l.tokens -= float64(n)
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / float64(l.rate) * float64(time.Second))
+	}
+	if deadline != 0 && now.Add(wait).UnixNano() > deadline {
+		l.tokens += float64(n)
+		l.Unlock()
+		return nil, os.ErrDeadlineExceeded
+	}
+	l.Unlock()
+	time.Sleep(wait)
+	return l, nil
+}
+
+// refund returns n unused bytes of budget taken by throttle
+func (l *rateLimiter) refund(n int) {
+	if l == nil || n <= 0 {
+		return
+	}
+	l.Lock()
+	defer l.Unlock()
+	l.tokens = min(l.tokens+float64(n), float64(l.rate))
+}
@@ -0,0 +1,44 @@
+package crunchio
+
+import (
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	const rate = 4000
+	b := NewBuffer("limited")
+	ref := b.Reference()
+	ref.Seek(3000, io.SeekStart)
+	b.SetRateLimit(rate)
+	chunk := make([]byte, 500)
+	start := time.Now()
+	// the bucket starts with a second of budget, so the 6000 bytes written
+	// between the buffer and its reference take at least half a second more
+	for i := 0; i < 6; i++ {
+		b.Write(chunk)
+		ref.Write(chunk)
+	}
+	if elapsed := time.Since(start); elapsed < 450*time.Millisecond {
+		t.Fatalf("writing 6000 bytes at %d bytes per second took %v", rate, elapsed)
+	}
+	if b.Size() != 6000 {
+		t.Fatalf("Size = %d, want 6000", b.Size())
+	}
+
+	b.SetWriteDeadline(time.Now().Add(50 * time.Millisecond))
+	if _, err := b.Write(make([]byte, rate)); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("Write that cannot get budget before its deadline = %v", err)
+	}
+	b.SetWriteDeadline(time.Time{})
+
+	b.SetRateLimit(0)
+	start = time.Now()
+	b.Write(make([]byte, 10*rate))
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Fatalf("Write with the limit removed took %v", elapsed)
+	}
+}
@@ -0,0 +1,283 @@
+package crunchio
+
+import (
+	"fmt"
+	"io"
+)
+
+// Reader is a read-only handle over the bytes of a Buffer with its own offset
+//
+// Reads only hold a read lock on the underlying buffer, so any number of
+// readers may read concurrently. Writing to the buffer while readers exist is
+// safe with respect to locking, but keeping readers consistent with those
+// writes is the caller's responsibility. A single Reader is not safe for
+// concurrent use.
+type Reader struct {
+	root   *Buffer
+	base   int64
+	end    int64
+	offset int64
+}
+
+func (b *Buffer) NewReader() *Reader {
+	if b == nil {
+		panic("NEWREADER: buffer is nil")
+	}
+	root, base, end := b.span()
+	return &Reader{root: root, base: base, end: end}
+}
+
+func (r *Reader) Read(dst []byte) (read int, err error) {
+	if r == nil {
+		panic("READ: reader is nil")
+	}
+	read, err = r.ReadAt(dst, r.offset)
+	r.offset += int64(read)
+	if err == io.EOF && read > 0 {
+		err = nil
+	}
+	return
+}
+
+func (r *Reader) ReadAt(dst []byte, offset int64) (read int, err error) {
+	if r == nil {
+		panic("READAT: reader is nil")
+	}
+	b := r.root
+	b.RLock()
+	defer b.RUnlock()
+	if b.closed {
+		return 0, fmt.Errorf("reader: readat: %w", ErrClosed)
+	}
+	var fileReader io.ReaderAt
+	if b.file != nil {
+		var ok bool
+		if fileReader, ok = b.file.(io.ReaderAt); !ok {
+			return 0, fmt.Errorf("reader: readat: file backing does not implement io.ReaderAt")
+		}
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("reader: readat: negative offset %d", offset)
+	}
+	length := visibleLength(b.length, r.base, r.end)
+	if offset >= length {
+		if b.stream {
+			return 0, nil
+		}
+		return 0, io.EOF
+	}
+	toRead := length - offset
+	if int(toRead) > len(dst) {
+		toRead = int64(len(dst))
+	}
+	if fileReader != nil {
+		if read, err = fileReader.ReadAt(dst[:toRead], r.base+offset); err != nil && err != io.EOF {
+			return read, fmt.Errorf("reader: readat: %w", err)
+		}
+		err = nil
+	} else {
+		read = copy(dst, b.buffer.ReadBytes(r.base+offset, toRead))
+	}
+	if read < len(dst) && !b.stream {
+		err = io.EOF
+	}
+	return
+}
+
+func (r *Reader) Seek(to int64, whence int) (offset int64, err error) {
+	if r == nil {
+		panic("SEEK: reader is nil")
+	}
+	switch whence {
+	case io.SeekStart:
+		offset = to
+	case io.SeekCurrent:
+		offset = r.offset + to
+	case io.SeekEnd:
+		r.root.RLock()
+		offset = visibleLength(r.root.length, r.base, r.end) - to
+		r.root.RUnlock()
+	default:
+		return r.offset, fmt.Errorf("reader: seek: invalid whence %d", whence)
+	}
+	if offset < 0 {
+		return r.offset, fmt.Errorf("reader: seek: negative offset %d", offset)
+	}
+	r.offset = offset
+	return
+}
+
+func (r *Reader) Tell() int64 {
+	if r == nil {
+		panic("TELL: reader is nil")
+	}
+	return r.offset
+}
+
+// NewReaderBuffer returns a read-only buffer over data positioned at offset 0,
+// standing in for a *bytes.Reader, with Len reporting the unread bytes and Size
+// the total length in the same way
+//
+// Unlike a bytes.Reader, an empty Read reports io.EOF even when bytes are left
+// unread, as it does on any non-stream buffer.
+func NewReaderBuffer(name string, data []byte) *Buffer {
+	b := NewBuffer(name, data)
+	b.readOnly = true
+	return b
+}
+
+const defaultBufferedReaderSize = 4096
+
+// BufferedReader serves reads from an in-memory window prefetched from a Buffer
+type BufferedReader struct {
+	src    *Buffer
+	window []byte
+	start  int
+	end    int
+	err    error
+}
+
+func NewBufferedReader(b *Buffer, size int) *BufferedReader {
+	if b == nil {
+		panic("NEWBUFFEREDREADER: buffer is nil")
+	}
+	if size <= 0 {
+		size = defaultBufferedReaderSize
+	}
+	return &BufferedReader{src: b, window: make([]byte, size)}
+}
+
+func (r *BufferedReader) fill() {
+	r.start, r.end = 0, 0
+	r.end, r.err = r.src.Read(r.window)
+}
+
+func (r *BufferedReader) readErr() error {
+	err := r.err
+	r.err = nil
+	return err
+}
+
+func (r *BufferedReader) Read(dst []byte) (read int, err error) {
+	if r == nil {
+		panic("READ: buffered reader is nil")
+	}
+	if len(dst) == 0 {
+		return 0, nil
+	}
+	if r.start == r.end {
+		if r.err != nil {
+			return 0, r.readErr()
+		}
+		if len(dst) >= len(r.window) {
+			return r.src.Read(dst)
+		}
+		r.fill()
+		if r.start == r.end {
+			return 0, r.readErr()
+		}
+	}
+	read = copy(dst, r.window[r.start:r.end])
+	r.start += read
+	return
+}
+
+func (r *BufferedReader) ReadByte() (byte, error) {
+	if r == nil {
+		panic("READBYTE: buffered reader is nil")
+	}
+	if r.start == r.end {
+		if r.err != nil {
+			return 0, r.readErr()
+		}
+		r.fill()
+		if r.start == r.end {
+			if err := r.readErr(); err != nil {
+				return 0, err
+			}
+			return 0, io.EOF
+		}
+	}
+	c := r.window[r.start]
+	r.start++
+	return c, nil
+}
+
+func (r *BufferedReader) Buffered() int {
+	if r == nil {
+		panic("BUFFERED: buffered reader is nil")
+	}
+	return r.end - r.start
+}
+
+// AsReaderAt returns an io.ReaderAt over the buffer and its length, suitable
+// for zip.NewReader and similar parsers
+func (b *Buffer) AsReaderAt() (io.ReaderAt, int64) {
+	if b == nil {
+		panic("ASREADERAT: buffer is nil")
+	}
+	return b, b.Size()
+}
+
+// multiReader reads a sequence of buffers through independent Readers
+type multiReader struct {
+	readers []*Reader
+}
+
+// NewMultiReader returns a reader over the concatenated contents of bufs,
+// reading them in order without moving their offsets
+func NewMultiReader(bufs ...*Buffer) io.Reader {
+	m := &multiReader{readers: make([]*Reader, len(bufs))}
+	for i := range bufs {
+		if bufs[i] == nil {
+			panic("NEWMULTIREADER: buffer is nil")
+		}
+		m.readers[i] = bufs[i].NewReader()
+	}
+	return m
+}
+
+func (m *multiReader) Read(dst []byte) (int, error) {
+	if len(dst) == 0 {
+		return 0, nil
+	}
+	for len(m.readers) > 0 {
+		read, err := m.readers[0].Read(dst)
+		if read > 0 {
+			return read, nil
+		}
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+		m.readers = m.readers[1:]
+	}
+	return 0, io.EOF
+}
+
+// teeReader copies everything read through a Reader into w
+type teeReader struct {
+	r *Reader
+	w io.Writer
+}
+
+// NewTeeReader returns a reader over b that writes each chunk it reads to w
+// before returning it, reading through its own Reader so the offset of b and
+// any other readers are left alone, a failed write is returned as the error
+func NewTeeReader(b *Buffer, w io.Writer) io.Reader {
+	if b == nil {
+		panic("NEWTEEREADER: buffer is nil")
+	}
+	return &teeReader{r: b.NewReader(), w: w}
+}
+
+func (t *teeReader) Read(dst []byte) (read int, err error) {
+	read, err = t.r.Read(dst)
+	if read > 0 {
+		if wrote, werr := t.w.Write(dst[:read]); werr != nil {
+			return read, werr
+		} else if wrote < read {
+			return read, io.ErrShortWrite
+		}
+	}
+	return
+}
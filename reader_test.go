@@ -0,0 +1,351 @@
+package crunchio
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+)
+
+// benchmarkData is read over and over by the concurrent read benchmarks
+var benchmarkData = bytes.Repeat([]byte("0123456789abcdef"), 4096)
+
+func BenchmarkReaderParallel(b *testing.B) {
+	buffer := NewBuffer("reader", benchmarkData)
+	b.SetBytes(1024)
+	b.RunParallel(func(pb *testing.PB) {
+		r := buffer.NewReader()
+		p := make([]byte, 1024)
+		for pb.Next() {
+			if _, err := r.Read(p); err == io.EOF {
+				r.Seek(0, io.SeekStart)
+			}
+		}
+	})
+}
+
+func BenchmarkReferenceParallel(b *testing.B) {
+	buffer := NewBuffer("reader", benchmarkData)
+	b.SetBytes(1024)
+	b.RunParallel(func(pb *testing.PB) {
+		r := buffer.Reference()
+		p := make([]byte, 1024)
+		for pb.Next() {
+			if _, err := r.Read(p); err == io.EOF {
+				r.Seek(0, io.SeekStart)
+			}
+		}
+	})
+}
+
+func TestBufferedReaderReadByte(t *testing.T) {
+	src := NewBuffer("buffered", benchmarkData[:1000])
+	r := NewBufferedReader(src, 64)
+	var got []byte
+	for {
+		c, err := r.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, c)
+	}
+	if !bytes.Equal(got, benchmarkData[:1000]) {
+		t.Fatalf("read %d bytes that differ from the source", len(got))
+	}
+	if _, err := r.ReadByte(); err != io.EOF {
+		t.Fatalf("ReadByte after the end = %v, want io.EOF", err)
+	}
+}
+
+func TestBufferedReaderRead(t *testing.T) {
+	src := NewBuffer("buffered", benchmarkData[:1000])
+	r := NewBufferedReader(src, 64)
+	c, _ := r.ReadByte()
+	if r.Buffered() != 63 {
+		t.Fatalf("Buffered = %d, want 63", r.Buffered())
+	}
+	rest, err := io.ReadAll(r)
+	if err != nil || !bytes.Equal(append([]byte{c}, rest...), benchmarkData[:1000]) {
+		t.Fatalf("ReadAll read %d bytes, %v", len(rest), err)
+	}
+}
+
+func BenchmarkBufferedReaderReadByte(b *testing.B) {
+	src := NewBuffer("buffered", benchmarkData)
+	r := NewBufferedReader(src, 0)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := r.ReadByte(); err == io.EOF {
+			src.Seek(0, io.SeekStart)
+		}
+	}
+}
+
+func BenchmarkBufferReadByte(b *testing.B) {
+	src := NewBuffer("buffered", benchmarkData)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := src.ReadByte(); err == io.EOF {
+			src.Seek(0, io.SeekStart)
+		}
+	}
+}
+
+func TestAsReaderAtZip(t *testing.T) {
+	b := NewBuffer("zip")
+	w := zip.NewWriter(b)
+	files := map[string]string{"a.txt": "alpha", "dir/b.txt": "bravo bravo bravo"}
+	for name, body := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(f, body)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the offset is left at the end by the writer, reads must not depend on it
+	r, err := zip.NewReader(b.AsReaderAt())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.File) != len(files) {
+		t.Fatalf("zip holds %d files, want %d", len(r.File), len(files))
+	}
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil || string(data) != files[f.Name] {
+			t.Fatalf("%s reads %q, %v, want %q", f.Name, data, err, files[f.Name])
+		}
+	}
+}
+
+func TestAsReaderAtTail(t *testing.T) {
+	b := NewBuffer("readerat", []byte("0123456789"))
+	b.Seek(7, io.SeekStart)
+	r, size := b.AsReaderAt()
+	if size != 10 {
+		t.Fatalf("size = %d, want 10", size)
+	}
+	p := make([]byte, 4)
+	if n, err := r.ReadAt(p, 8); n != 2 || err != io.EOF || string(p[:n]) != "89" {
+		t.Fatalf("short tail ReadAt = %d, %v, %q, want 2, io.EOF", n, err, p[:n])
+	}
+	if n, err := r.ReadAt(p, 0); n != 4 || err != nil || string(p) != "0123" {
+		t.Fatalf("ReadAt = %d, %v, %q", n, err, p)
+	}
+	if b.Tell() != 7 {
+		t.Fatalf("ReadAt moved the offset to %d", b.Tell())
+	}
+}
+
+func TestMultiReader(t *testing.T) {
+	parts := []*Buffer{
+		NewBuffer("first", []byte("alpha ")),
+		NewBuffer("empty"),
+		NewBuffer("second", []byte("bravo ")),
+		NewBuffer("third", []byte("charlie")),
+	}
+	parts[2].Seek(3, io.SeekStart)
+	want := []byte("alpha bravo charlie")
+	got, err := io.ReadAll(NewMultiReader(parts...))
+	if err != nil || !bytes.Equal(got, want) {
+		t.Fatalf("ReadAll = %q, %v, want %q", got, err, want)
+	}
+	if parts[2].Tell() != 3 || parts[0].Tell() != 0 {
+		t.Fatalf("multi reader moved the sources to %d and %d", parts[0].Tell(), parts[2].Tell())
+	}
+
+	r := NewMultiReader(parts...)
+	p := make([]byte, 4)
+	var small []byte
+	for {
+		n, err := r.Read(p)
+		small = append(small, p[:n]...)
+		if err == io.EOF {
+			break
+		}
+		if err != nil || n == 0 {
+			t.Fatalf("Read = %d, %v", n, err)
+		}
+	}
+	if !bytes.Equal(small, want) {
+		t.Fatalf("small reads = %q, want %q", small, want)
+	}
+	if n, err := NewMultiReader().Read(p); n != 0 || err != io.EOF {
+		t.Fatalf("empty multi reader Read = %d, %v, want 0, io.EOF", n, err)
+	}
+}
+
+// failingWriter accepts limit bytes and then fails every write
+type failingWriter struct {
+	limit int
+}
+
+func (f *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > f.limit {
+		wrote := f.limit
+		f.limit = 0
+		return wrote, io.ErrClosedPipe
+	}
+	f.limit -= len(p)
+	return len(p), nil
+}
+
+func TestTeeReader(t *testing.T) {
+	src := NewBuffer("tee", []byte("the consumed stream"))
+	src.Seek(4, io.SeekStart)
+	target := NewBuffer("target")
+	r := NewTeeReader(src, target)
+	p := make([]byte, 5)
+	var read []byte
+	for {
+		n, err := r.Read(p)
+		read = append(read, p[:n]...)
+		if !bytes.Equal(target.Bytes(), read) {
+			t.Fatalf("tee target holds %q after reading %q", target.Bytes(), read)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if string(read) != "the consumed stream" {
+		t.Fatalf("tee reader read %q", read)
+	}
+	if src.Tell() != 4 {
+		t.Fatalf("tee reader moved the source to %d", src.Tell())
+	}
+
+	r = NewTeeReader(src, &failingWriter{limit: 7})
+	if n, err := r.Read(make([]byte, 4)); n != 4 || err != nil {
+		t.Fatalf("Read = %d, %v", n, err)
+	}
+	if _, err := r.Read(make([]byte, 4)); err != io.ErrClosedPipe {
+		t.Fatalf("Read with a failing tee target = %v, want its error", err)
+	}
+}
+
+// The Reader tests mirror a subset of the bytes.Reader tests, running every
+// step against a bytes.Reader over the same data and comparing the results
+
+func TestReaderBufferSeekRead(t *testing.T) {
+	const data = "0123456789"
+	want := bytes.NewReader([]byte(data))
+	got := NewReaderBuffer("reader", []byte(data))
+	for i, step := range []struct {
+		off    int64
+		whence int
+		n      int
+	}{
+		{0, io.SeekStart, 20},
+		{1, io.SeekStart, 1},
+		{1, io.SeekCurrent, 2},
+		{5, io.SeekStart, 1},
+		{1, io.SeekCurrent, 1},
+		{20, io.SeekStart, 1},
+		{3, io.SeekStart, 10},
+	} {
+		wantPos, _ := want.Seek(step.off, step.whence)
+		gotPos, err := got.Seek(step.off, step.whence)
+		if err != nil || gotPos != wantPos {
+			t.Fatalf("step %d: Seek = %d, %v, want %d", i, gotPos, err, wantPos)
+		}
+		wantBuf, gotBuf := make([]byte, step.n), make([]byte, step.n)
+		wantN, wantErr := want.Read(wantBuf)
+		gotN, gotErr := got.Read(gotBuf)
+		if gotN != wantN || gotErr != wantErr || !bytes.Equal(gotBuf[:gotN], wantBuf[:wantN]) {
+			t.Fatalf("step %d: Read = %d, %v, %q, want %d, %v, %q", i, gotN, gotErr, gotBuf[:gotN], wantN, wantErr, wantBuf[:wantN])
+		}
+		if got.Len() != want.Len() || got.Size() != want.Size() {
+			t.Fatalf("step %d: Len, Size = %d, %d, want %d, %d", i, got.Len(), got.Size(), want.Len(), want.Size())
+		}
+	}
+}
+
+func TestReaderBufferLen(t *testing.T) {
+	const data = "hello world"
+	r := NewReaderBuffer("reader", []byte(data))
+	if r.Len() != 11 || r.Size() != 11 {
+		t.Fatalf("Len, Size = %d, %d, want 11, 11", r.Len(), r.Size())
+	}
+	if n, err := r.Read(make([]byte, 10)); n != 10 || err != nil {
+		t.Fatalf("Read = %d, %v", n, err)
+	}
+	if r.Len() != 1 || r.Size() != 11 {
+		t.Fatalf("Len, Size = %d, %d, want 1, 11", r.Len(), r.Size())
+	}
+	if n, err := r.Read(make([]byte, 1)); n != 1 || err != nil {
+		t.Fatalf("Read = %d, %v", n, err)
+	}
+	if r.Len() != 0 || r.Size() != 11 {
+		t.Fatalf("Len, Size = %d, %d, want 0, 11", r.Len(), r.Size())
+	}
+}
+
+func TestReaderBufferReadAt(t *testing.T) {
+	const data = "0123456789"
+	want := bytes.NewReader([]byte(data))
+	got := NewReaderBuffer("reader", []byte(data))
+	for _, tt := range []struct {
+		off int64
+		n   int
+	}{
+		{0, 10},
+		{1, 10},
+		{1, 9},
+		{11, 10},
+	} {
+		wantBuf, gotBuf := make([]byte, tt.n), make([]byte, tt.n)
+		wantN, wantErr := want.ReadAt(wantBuf, tt.off)
+		gotN, gotErr := got.ReadAt(gotBuf, tt.off)
+		if gotN != wantN || gotErr != wantErr || !bytes.Equal(gotBuf[:gotN], wantBuf[:wantN]) {
+			t.Fatalf("ReadAt(%d, %d) = %d, %v, %q, want %d, %v, %q", tt.n, tt.off, gotN, gotErr, gotBuf[:gotN], wantN, wantErr, wantBuf[:wantN])
+		}
+	}
+	if _, err := got.ReadAt(make([]byte, 1), -1); err == nil {
+		t.Fatal("ReadAt at a negative offset succeeded")
+	}
+	if got.Len() != 10 {
+		t.Fatalf("ReadAt moved the offset, Len = %d", got.Len())
+	}
+}
+
+func TestReaderBufferReadOnly(t *testing.T) {
+	r := NewReaderBuffer("reader", []byte("data"))
+	if _, err := r.Write([]byte("x")); err == nil {
+		t.Fatal("Write to a reader buffer succeeded")
+	}
+	data, err := io.ReadAll(r)
+	if err != nil || string(data) != "data" {
+		t.Fatalf("ReadAll = %q, %v", data, err)
+	}
+}
+
+func TestReaderSharesReads(t *testing.T) {
+	b := NewBuffer("reader", []byte("abcdef"))
+	r1, r2 := b.NewReader(), b.NewReader()
+	p := make([]byte, 3)
+	r1.Read(p)
+	if n, err := r2.Read(p); n != 3 || err != nil || string(p) != "abc" {
+		t.Fatalf("second reader read %q, %v", p[:n], err)
+	}
+	if n, err := r1.Read(p); n != 3 || err != nil || string(p) != "def" {
+		t.Fatalf("first reader read %q, %v", p[:n], err)
+	}
+	if b.Tell() != 0 {
+		t.Fatalf("readers moved the buffer to %d", b.Tell())
+	}
+}
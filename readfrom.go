@@ -0,0 +1,35 @@
+package crunchio
+
+import "io"
+
+// readFromChunk is the size of the chunk ReadFrom reads into before writing
+const readFromChunk = 32 * 1024
+
+// ReadFrom writes everything read from r at the current offset until io.EOF,
+// growing the buffer as needed and reusing one chunk for every read,
+// implementing io.ReaderFrom so io.Copy skips its intermediate buffer
+func (b *Buffer) ReadFrom(r io.Reader) (n int64, err error) {
+	if b == nil {
+		panic("READFROM: buffer is nil")
+	}
+	if b.tracer != nil {
+		defer b.tracer.StartSpan("ReadFrom " + b.name)()
+	}
+	chunk := make([]byte, readFromChunk)
+	for {
+		read, readErr := r.Read(chunk)
+		if read > 0 {
+			wrote, writeErr := b.Write(chunk[:read])
+			n += int64(wrote)
+			if writeErr != nil {
+				return n, writeErr
+			}
+		}
+		if readErr == io.EOF {
+			return n, nil
+		}
+		if readErr != nil {
+			return n, readErr
+		}
+	}
+}
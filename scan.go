@@ -0,0 +1,111 @@
+package crunchio
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Scan returns the absolute offsets of every match of pattern in the buffer,
+// including overlapping ones, where a byte matches when it equals the pattern
+// byte in the bits set in the corresponding mask byte, a nil mask matches
+// every bit
+func (b *Buffer) Scan(pattern, mask []byte) ([]int64, error) {
+	if b == nil {
+		panic("SCAN: buffer is nil")
+	}
+	if mask == nil {
+		mask = make([]byte, len(pattern))
+		for i := range mask {
+			mask[i] = 0xFF
+		}
+	}
+	if len(mask) != len(pattern) {
+		return nil, fmt.Errorf("buffer: scan: mask length %d does not match pattern length %d", len(mask), len(pattern))
+	}
+	if len(pattern) == 0 {
+		return nil, fmt.Errorf("buffer: scan: empty pattern")
+	}
+	length := b.Size()
+	width := int64(len(pattern))
+	var matches []int64
+	chunk := make([]byte, searchChunk+width-1)
+	for at := int64(0); at+width <= length; at += searchChunk {
+		window := chunk[:min(int64(len(chunk)), length-at)]
+		if err := b.readExactAt(window, at); err != nil {
+			return matches, err
+		}
+		for i := 0; i+len(pattern) <= len(window) && i < searchChunk; i++ {
+			if maskedMatch(window[i:], pattern, mask) {
+				matches = append(matches, at+int64(i))
+			}
+		}
+	}
+	return matches, nil
+}
+
+// ScanSignature is Scan for a signature written as space-separated hex bytes,
+// where "??" matches any byte and a "?" in place of one hex digit matches any
+// value of that nibble, as in "4D 5A ?? ?? 5?"
+func (b *Buffer) ScanSignature(signature string) ([]int64, error) {
+	if b == nil {
+		panic("SCANSIGNATURE: buffer is nil")
+	}
+	pattern, mask, err := ParseSignature(signature)
+	if err != nil {
+		return nil, err
+	}
+	return b.Scan(pattern, mask)
+}
+
+// ParseSignature converts a signature in the form taken by ScanSignature into
+// the pattern and mask taken by Scan
+func ParseSignature(signature string) (pattern, mask []byte, err error) {
+	for _, token := range strings.Fields(signature) {
+		if token == "?" {
+			token = "??"
+		}
+		if len(token) != 2 {
+			return nil, nil, fmt.Errorf("buffer: scan: invalid signature byte %q", token)
+		}
+		var value, bits byte
+		for _, digit := range token {
+			value <<= 4
+			bits <<= 4
+			if digit == '?' {
+				continue
+			}
+			nibble, ok := hexNibble(digit)
+			if !ok {
+				return nil, nil, fmt.Errorf("buffer: scan: invalid signature byte %q", token)
+			}
+			value |= nibble
+			bits |= 0xF
+		}
+		pattern = append(pattern, value)
+		mask = append(mask, bits)
+	}
+	return
+}
+
+// hexNibble decodes a single hex digit
+func hexNibble(digit rune) (byte, bool) {
+	switch {
+	case digit >= '0' && digit <= '9':
+		return byte(digit - '0'), true
+	case digit >= 'a' && digit <= 'f':
+		return byte(digit - 'a' + 10), true
+	case digit >= 'A' && digit <= 'F':
+		return byte(digit - 'A' + 10), true
+	}
+	return 0, false
+}
+
+// maskedMatch reports whether data starts with pattern under mask
+func maskedMatch(data, pattern, mask []byte) bool {
+	for i := range pattern {
+		if (data[i]^pattern[i])&mask[i] != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+package crunchio
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+func TestScan(t *testing.T) {
+	b := NewBuffer("scan", []byte{0x4D, 0x5A, 0x90, 0x00, 0x4D, 0x5A, 0x52, 0x4D, 0x4D})
+	for _, tt := range []struct {
+		pattern, mask []byte
+		want          []int64
+	}{
+		{[]byte{0x4D, 0x5A}, nil, []int64{0, 4}},
+		{[]byte{0x4D, 0x00, 0x50}, []byte{0xFF, 0x00, 0xF0}, []int64{4}},
+		{[]byte{0x4D, 0x4D}, nil, []int64{7}},
+		{[]byte{0x4D}, nil, []int64{0, 4, 7, 8}},
+		{[]byte{0x12, 0x34}, nil, nil},
+		{bytes.Repeat([]byte{0x4D}, 10), nil, nil},
+	} {
+		if got, err := b.Scan(tt.pattern, tt.mask); !slices.Equal(got, tt.want) || err != nil {
+			t.Fatalf("Scan(% x, % x) = %v, %v, want %v", tt.pattern, tt.mask, got, err, tt.want)
+		}
+	}
+	if _, err := b.Scan(nil, nil); err == nil {
+		t.Fatal("Scan of an empty pattern succeeded")
+	}
+	if _, err := b.Scan([]byte{1, 2}, []byte{0xFF}); err == nil {
+		t.Fatal("Scan with a mismatched mask succeeded")
+	}
+}
+
+func TestScanOverlappingAndChunks(t *testing.T) {
+	data := bytes.Repeat([]byte{'.'}, 2*searchChunk)
+	copy(data[searchChunk-2:], "aaaa")
+	b := NewBuffer("scan", data)
+	want := []int64{searchChunk - 2, searchChunk - 1, searchChunk}
+	if got, err := b.Scan([]byte("aa"), nil); !slices.Equal(got, want) || err != nil {
+		t.Fatalf("Scan across a chunk boundary = %v, %v, want %v", got, err, want)
+	}
+}
+
+func TestScanSlice(t *testing.T) {
+	b := NewBuffer("scan", []byte("abXabXab"))
+	s := b.Slice(1, 5)
+	if got, err := s.Scan([]byte("ab"), nil); !slices.Equal(got, []int64{2}) || err != nil {
+		t.Fatalf("Scan in a slice = %v, %v, want [2]", got, err)
+	}
+	if got, err := s.Scan([]byte("Xa"), nil); !slices.Equal(got, []int64{1}) || err != nil {
+		t.Fatalf("Scan of a match at the window edge = %v, %v, want [1]", got, err)
+	}
+}
+
+func TestScanSignature(t *testing.T) {
+	b := NewBuffer("scan", []byte{0x4D, 0x5A, 0x90, 0x00, 0x4D, 0x5A, 0x52})
+	if got, err := b.ScanSignature("4D 5A ?? ?? 4d"); !slices.Equal(got, []int64{0}) || err != nil {
+		t.Fatalf("ScanSignature with wildcards = %v, %v", got, err)
+	}
+	if got, err := b.ScanSignature("5A 5?"); !slices.Equal(got, []int64{5}) || err != nil {
+		t.Fatalf("ScanSignature with a nibble wildcard = %v, %v", got, err)
+	}
+	for _, signature := range []string{"4D 5", "4D ZZ", "4D 5A5A", ""} {
+		if _, err := b.ScanSignature(signature); err == nil {
+			t.Fatalf("ScanSignature(%q) succeeded", signature)
+		}
+	}
+	pattern, mask, err := ParseSignature("A? ? 0F")
+	if err != nil || !slices.Equal(pattern, []byte{0xA0, 0x00, 0x0F}) || !slices.Equal(mask, []byte{0xF0, 0x00, 0xFF}) {
+		t.Fatalf("ParseSignature = % x, % x, %v", pattern, mask, err)
+	}
+}
@@ -0,0 +1,182 @@
+package crunchio
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+
+	crunch "github.com/superwhiskers/crunch/v3"
+)
+
+// Replace replaces up to n non-overlapping occurrences of old with new, or all
+// of them when n is negative, returning how many were replaced
+//
+// An empty old never matches. When the contents shrink, the offset of the
+// buffer and of every reference sharing its bytes is pulled back to the new
+// end like after a Truncate. Replacing on a slice fails, since it would move
+// bytes across the edges of its window.
+func (b *Buffer) Replace(old, new []byte, n int) (int, error) {
+	if b == nil {
+		panic("REPLACE: buffer is nil")
+	}
+	b.Lock()
+	defer b.Unlock()
+	if b.Closed() {
+		return 0, fmt.Errorf("buffer: replace: %w", ErrClosed)
+	}
+	if b.readOnly {
+		return 0, fmt.Errorf("buffer: replace: %w", ErrReadOnly)
+	}
+	if len(old) == 0 || n == 0 {
+		return 0, nil
+	}
+	b.unshare()
+	if b.parent != nil {
+		if err := b.windowResize("replace"); err != nil {
+			return 0, err
+		}
+		count, err := b.parent.Replace(old, new, n)
+		b.clampTruncated()
+		return count, err
+	}
+	data := b.snapshot()
+	count := 0
+	for at := 0; n < 0 || count < n; count++ {
+		i := bytes.Index(data[at:], old)
+		if i < 0 {
+			break
+		}
+		at += i + len(old)
+	}
+	if count == 0 {
+		return 0, nil
+	}
+	if err := b.setContents(bytes.Replace(data, old, new, count)); err != nil {
+		return 0, fmt.Errorf("buffer: replace: %w", err)
+	}
+	if b.offset > b.length {
+		b.offset = b.length
+		b.bit = 0
+	}
+	b.truncations.Add(1)
+	return count, nil
+}
+
+// Count returns the number of non-overlapping occurrences of sep in the whole
+// buffer, an empty sep never matches and counts as zero
+func (b *Buffer) Count(sep []byte) int {
+	if b == nil {
+		panic("COUNT: buffer is nil")
+	}
+	if len(sep) == 0 {
+		return 0
+	}
+	b.Lock()
+	defer b.Unlock()
+	if b.Closed() {
+		return 0
+	}
+	return bytes.Count(b.snapshot(), sep)
+}
+
+// Swap replaces the contents of the buffer with src in one step, rewinding the
+// offset, and returns the previous contents, references sharing the bytes have
+// their offsets pulled back to the new end like after a Truncate
+//
+// Closed and read-only buffers, and swaps that would exceed the limit set with
+// SetLimit, are left untouched and return nil.
+func (b *Buffer) Swap(src []byte) []byte {
+	if b == nil {
+		panic("SWAP: buffer is nil")
+	}
+	b.Lock()
+	defer b.Unlock()
+	if b.Closed() || b.readOnly {
+		return nil
+	}
+	b.unshare()
+	if b.parent != nil {
+		if b.windowed {
+			return nil
+		}
+		old := b.parent.Swap(src)
+		if old != nil {
+			b.offset = 0
+			b.bit = 0
+		}
+		return old
+	}
+	if err := b.checkLimit("swap", int64(len(src))); err != nil {
+		return nil
+	}
+	old := b.snapshot()
+	if b.file != nil {
+		if err := b.setContents(src); err != nil {
+			return nil
+		}
+	} else {
+		b.setBacking(crunch.NewBuffer(append([]byte{}, src...)))
+		b.length = int64(len(src))
+	}
+	b.offset = 0
+	b.bit = 0
+	b.truncations.Add(1)
+	return old
+}
+
+// setContents replaces every byte of the buffer with data, the caller must
+// hold the lock of a buffer that owns its bytes
+func (b *Buffer) setContents(data []byte) error {
+	if err := b.checkLimit("write", int64(len(data))); err != nil {
+		return err
+	}
+	if b.file != nil {
+		if _, err := b.writeFileAt(data, 0); err != nil {
+			return err
+		}
+		if int64(len(data)) < b.length {
+			if t, ok := b.file.(interface{ Truncate(int64) error }); ok {
+				if err := t.Truncate(int64(len(data))); err != nil {
+					return fmt.Errorf("buffer: write: %w", err)
+				}
+			}
+		}
+		b.length = int64(len(data))
+		return nil
+	}
+	buffer := b.Buffer()
+	if buffer == nil {
+		return fmt.Errorf("buffer: write: crunch buffer vanished")
+	}
+	if b.length > int64(len(data)) {
+		b.length = int64(len(data))
+	}
+	b.grow(buffer, 0, int64(len(data)))
+	buffer.WriteBytes(0, data)
+	b.writes.Add(1)
+	return nil
+}
+
+// setSharedContents is setContents for any buffer, replacing the bytes visible
+// to a reference in the buffer that owns them, the caller must hold b's lock
+func (b *Buffer) setSharedContents(data []byte) error {
+	root, base, end := b.span()
+	if root != b {
+		root.Lock()
+		defer root.Unlock()
+	}
+	if base != 0 || end != math.MaxInt64 {
+		// a slice can only take contents that overwrite or extend its window
+		// in place, anything shorter would move bytes across its edges
+		if int64(len(data)) < visibleLength(root.length, base, end) || base+int64(len(data)) > end {
+			return fmt.Errorf("cannot resize a slice")
+		}
+		if _, err := root.writeOffsetLocked(data, base); err != nil {
+			return err
+		}
+	} else if err := root.setContents(data); err != nil {
+		return err
+	}
+	b.length = visibleLength(root.length, base, end)
+	return nil
+}
@@ -0,0 +1,195 @@
+package crunchio
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+)
+
+func TestReplace(t *testing.T) {
+	for _, tt := range []struct {
+		data, old, new string
+		n              int
+		want           string
+		count          int
+	}{
+		{"a-b-c", "-", "+", -1, "a+b+c", 2},
+		{"a--b--c", "--", "-", -1, "a-b-c", 2},
+		{"a-b-c", "-", "<->", -1, "a<->b<->c", 2},
+		{"a-b-c", "-", "", 1, "ab-c", 1},
+		{"aaaa", "aa", "b", -1, "bb", 2},
+		{"aaa", "aa", "b", -1, "ba", 1},
+		{"abc", "x", "y", -1, "abc", 0},
+		{"abc", "", "y", -1, "abc", 0},
+		{"abc", "b", "y", 0, "abc", 0},
+	} {
+		b := NewBuffer("replace", []byte(tt.data))
+		if count, err := b.Replace([]byte(tt.old), []byte(tt.new), tt.n); count != tt.count || err != nil {
+			t.Fatalf("Replace(%q, %q, %q, %d) = %d, %v, want %d", tt.data, tt.old, tt.new, tt.n, count, err, tt.count)
+		}
+		if got := b.String(); got != tt.want || b.Size() != int64(len(tt.want)) {
+			t.Fatalf("Replace(%q, %q, %q, %d) left %q, want %q", tt.data, tt.old, tt.new, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestReplaceReference(t *testing.T) {
+	b := NewBuffer("replace", []byte("key=old"))
+	ref := b.Reference()
+	if count, err := ref.Replace([]byte("old"), []byte("newer"), -1); count != 1 || err != nil {
+		t.Fatalf("Replace through a reference = %d, %v, want 1", count, err)
+	}
+	if got := b.String(); got != "key=newer" {
+		t.Fatalf("parent holds %q", got)
+	}
+	if count, err := b.ReadOnly().Replace([]byte("key"), []byte("k"), -1); count != 0 || !errors.Is(err, ErrReadOnly) || b.String() != "key=newer" {
+		t.Fatalf("Replace on a read-only view = %d, %v, parent holds %q", count, err, b.String())
+	}
+	if count, err := b.Slice(0, 3).Replace([]byte("key"), []byte("k"), -1); count != 0 || err == nil || b.String() != "key=newer" {
+		t.Fatalf("Replace on a slice = %d, %v, parent holds %q", count, err, b.String())
+	}
+}
+
+func TestReplaceShrinkClampsOffsets(t *testing.T) {
+	b := NewBuffer("replace", []byte("aaaaaaaaaa"))
+	ref := b.Reference()
+	ref.Seek(0, io.SeekEnd)
+	b.Seek(0, io.SeekEnd)
+	b.SeekBit(b.TellBit() - 3)
+	if count, err := b.Replace([]byte("aaaaa"), []byte("b"), -1); count != 2 || err != nil {
+		t.Fatalf("Replace = %d, %v, want 2", count, err)
+	}
+	if b.Tell() != 2 || b.TellBit() != 16 || b.Size() != 2 {
+		t.Fatalf("Replace left the offset at %d, bit %d with Size %d, want 2, 16, 2", b.Tell(), b.TellBit(), b.Size())
+	}
+	if ref.Tell() != 2 {
+		t.Fatalf("Replace left a reference at %d, want 2", ref.Tell())
+	}
+	b.Write([]byte("c"))
+	if got := b.String(); got != "bbc" {
+		t.Fatalf("Write after a shrinking Replace left %q, want no zero padding", got)
+	}
+}
+
+func TestReplaceErrors(t *testing.T) {
+	b := NewBuffer("replace", []byte("abc"))
+	b.SetLimit(4)
+	if count, err := b.Replace([]byte("b"), []byte("0123"), -1); count != 0 || !errors.Is(err, ErrTooLarge) || b.String() != "abc" {
+		t.Fatalf("Replace past the limit = %d, %v, buffer holds %q", count, err, b.String())
+	}
+	b.Close()
+	if count, err := b.Replace([]byte("a"), []byte("b"), -1); count != 0 || !errors.Is(err, ErrClosed) {
+		t.Fatalf("Replace after Close = %d, %v, want ErrClosed", count, err)
+	}
+}
+
+func TestCount(t *testing.T) {
+	for _, tt := range []struct {
+		data, sep string
+		want      int
+	}{
+		{"abc", "", 0},
+		{"", "a", 0},
+		{"a,b,,c", ",", 3},
+		{"aaaa", "a", 4},
+		{"aaaa", "aa", 2},
+		{"aaa", "aa", 1},
+		{"abababa", "aba", 2},
+		{"xyzxyz", "xyz", 2},
+		{"xyzxy", "xyz", 1},
+	} {
+		b := NewBuffer("count", []byte(tt.data))
+		b.Seek(2, io.SeekStart)
+		if got := b.Count([]byte(tt.sep)); got != tt.want {
+			t.Fatalf("Count(%q, %q) = %d, want %d", tt.data, tt.sep, got, tt.want)
+		}
+		if b.Tell() != 2 {
+			t.Fatalf("Count moved the offset to %d", b.Tell())
+		}
+	}
+	b := NewBuffer("count", []byte("a-b-c-d"))
+	if got := b.Slice(2, 3).Count([]byte("-")); got != 1 {
+		t.Fatalf("Count over a slice = %d, want 1", got)
+	}
+	if got := b.Reference().Count([]byte("-")); got != 3 {
+		t.Fatalf("Count through a reference = %d, want 3", got)
+	}
+}
+
+func TestSwap(t *testing.T) {
+	b := NewBuffer("swap", []byte("first generation"))
+	reader := b.NewReader()
+	p := make([]byte, 5)
+	reader.Read(p)
+	b.Seek(6, io.SeekStart)
+	src := []byte("second")
+	old := b.Swap(src)
+	if string(old) != "first generation" || string(p) != "first" {
+		t.Fatalf("Swap returned %q after the reader saw %q", old, p)
+	}
+	src[0] = 'S'
+	if got := b.String(); got != "second" || b.Tell() != 0 || b.Size() != 6 {
+		t.Fatalf("Swap left %q at offset %d", got, b.Tell())
+	}
+	if got, err := io.ReadAll(b.NewReader()); err != nil || string(got) != "second" {
+		t.Fatalf("reader after Swap read %q, %v", got, err)
+	}
+	if old := b.Reference().Swap([]byte("third")); string(old) != "second" || b.String() != "third" {
+		t.Fatalf("Swap through a reference returned %q, parent holds %q", old, b.String())
+	}
+	if old := b.ReadOnly().Swap([]byte("x")); old != nil || b.String() != "third" {
+		t.Fatalf("Swap on a read-only view returned %q, parent holds %q", old, b.String())
+	}
+}
+
+func TestSwapLimit(t *testing.T) {
+	b := NewBuffer("swap", []byte("abc"))
+	b.SetLimit(4)
+	if old := b.Swap([]byte("0123456789")); old != nil || b.String() != "abc" {
+		t.Fatalf("Swap past the limit returned %q, buffer holds %q", old, b.String())
+	}
+	if old := b.Reference().Swap([]byte("0123456789")); old != nil || b.String() != "abc" {
+		t.Fatalf("Swap past the limit through a reference returned %q, buffer holds %q", old, b.String())
+	}
+	if old := b.Swap([]byte("wxyz")); string(old) != "abc" || b.String() != "wxyz" {
+		t.Fatalf("Swap within the limit returned %q, buffer holds %q", old, b.String())
+	}
+}
+
+func TestSwapClampsReferences(t *testing.T) {
+	b := NewBuffer("swap", []byte("0123456789"))
+	ref := b.Reference()
+	ref.Seek(8, io.SeekStart)
+	b.Swap([]byte("abc"))
+	if ref.Tell() != 3 {
+		t.Fatalf("reference at %d after Swap to 3 bytes, want 3", ref.Tell())
+	}
+	if _, err := ref.Write([]byte("d")); err != nil || b.String() != "abcd" {
+		t.Fatalf("reference Write after Swap = %v, buffer holds %q", err, b.String())
+	}
+}
+
+func TestSwapConcurrentReaders(t *testing.T) {
+	generations := [][]byte{bytes.Repeat([]byte{'a'}, 64), bytes.Repeat([]byte{'b'}, 64)}
+	b := NewBuffer("swap", generations[0])
+	var wg sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				data := b.Bytes()
+				if len(data) != 64 || bytes.Count(data, data[:1]) != 64 {
+					t.Errorf("reader saw a mix of generations: %q", data)
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 500; i++ {
+		b.Swap(generations[i%2])
+	}
+	wg.Wait()
+}
@@ -0,0 +1,84 @@
+package crunchio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrSliceBounds is returned by writes that would run past the end of a slice
+var ErrSliceBounds = errors.New("write past end of slice")
+
+// Slice returns a view of the n bytes of b starting at off, reads and writes
+// on it go to b but are confined to that window, reads reporting io.EOF at its
+// end and writes that would run past it returning ErrSliceBounds
+//
+// The slice is a reference to b, its length is the part of the window b holds
+// at the time of each call, so it grows and shrinks along with b, and it may
+// be written up to its full n bytes, extending b as needed. Its offsets are
+// relative to off, and its own offset, hooks and settings are independent of
+// b's. Operations that would insert or remove bytes, moving b's bytes across
+// the edges of the window, return an error on a slice.
+func (b *Buffer) Slice(off, n int64) *Buffer {
+	if b == nil {
+		panic("SLICE: buffer is nil")
+	}
+	if off < 0 || n < 0 {
+		panic(fmt.Sprintf("SLICE: invalid window [%d, %d+%d)", off, off, n))
+	}
+	nb := b.Reference()
+	nb.windowed = true
+	nb.windowBase = off
+	nb.windowLen = n
+	return nb
+}
+
+// windowRead shortens dst to the part of it that fits in a slice's window from
+// offset, returning it along with the matching offset in the parent, the
+// caller must hold b's lock
+func (b *Buffer) windowRead(dst []byte, offset int64) ([]byte, int64) {
+	if !b.windowed || offset < 0 {
+		return dst, offset
+	}
+	if rest := max(b.windowLen-offset, 0); int64(len(dst)) > rest {
+		dst = dst[:rest]
+	}
+	return dst, b.windowBase + offset
+}
+
+// windowExact translates offset into the parent of a slice for a read of
+// exactly n bytes, failing like readExactAt if they run past its window
+func (b *Buffer) windowExact(n int, offset int64) (int64, error) {
+	if !b.windowed || offset < 0 {
+		return offset, nil
+	}
+	if rest := b.windowLen - offset; rest < int64(n) {
+		if rest <= 0 {
+			return 0, io.EOF
+		}
+		return 0, io.ErrUnexpectedEOF
+	}
+	return b.windowBase + offset, nil
+}
+
+// windowWrite translates offset into the parent of a slice for a write of n
+// bytes, failing with ErrSliceBounds if they run past its window
+func (b *Buffer) windowWrite(op string, n int, offset int64) (int64, error) {
+	if !b.windowed || offset < 0 {
+		return offset, nil
+	}
+	if offset+int64(n) > b.windowLen {
+		return 0, fmt.Errorf("buffer: %s: %d bytes at %d of %d: %w", op, n, offset, b.windowLen, ErrSliceBounds)
+	}
+	return b.windowBase + offset, nil
+}
+
+// windowResize returns an error for op if b is a slice, since inserting or
+// removing bytes would move them across its edges, references to a slice
+// reach it on the way to the root
+func (b *Buffer) windowResize(op string) error {
+	if b.windowed {
+		return fmt.Errorf("buffer: %s: cannot resize a slice", op)
+	}
+	return nil
+}
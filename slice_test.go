@@ -0,0 +1,161 @@
+package crunchio
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"testing"
+	"unsafe"
+)
+
+func TestSliceReadWrite(t *testing.T) {
+	b := NewBuffer("slice", []byte("0123456789"))
+	s := b.Slice(2, 4)
+	if s.Size() != 4 {
+		t.Fatalf("Size = %d, want 4", s.Size())
+	}
+	p := make([]byte, 8)
+	if n, err := s.Read(p); n != 4 || err != nil || string(p[:n]) != "2345" {
+		t.Fatalf("Read = %d, %v, %q", n, err, p[:n])
+	}
+	if n, err := s.Read(p); n != 0 || err != io.EOF {
+		t.Fatalf("Read at end of window = %d, %v, want 0, io.EOF", n, err)
+	}
+	if n, err := s.ReadAt(p[:3], 2); n != 2 || err != io.EOF || string(p[:n]) != "45" {
+		t.Fatalf("ReadAt across end of window = %d, %v, %q", n, err, p[:n])
+	}
+	if _, err := s.ReadU32(); err != io.EOF {
+		t.Fatalf("ReadU32 at end of window = %v, want io.EOF", err)
+	}
+	if n, err := s.WriteAt([]byte("ab"), 1); n != 2 || err != nil {
+		t.Fatalf("WriteAt = %d, %v", n, err)
+	}
+	if _, err := s.WriteAt([]byte("xyz"), 2); !errors.Is(err, ErrSliceBounds) {
+		t.Fatalf("WriteAt past end of window = %v, want ErrSliceBounds", err)
+	}
+	if got := b.String(); got != "012ab56789" {
+		t.Fatalf("parent holds %q", got)
+	}
+	if got := s.String(); got != "2ab5" {
+		t.Fatalf("slice holds %q", got)
+	}
+}
+
+func TestSliceFollowsParentLength(t *testing.T) {
+	b := NewBuffer("slice", []byte("abc"))
+	s := b.Slice(1, 6)
+	if s.Size() != 2 {
+		t.Fatalf("Size = %d, want 2", s.Size())
+	}
+	b.Seek(0, io.SeekEnd)
+	b.Write([]byte("defghij"))
+	if s.Size() != 6 {
+		t.Fatalf("Size after parent grew = %d, want 6", s.Size())
+	}
+	if got := s.String(); got != "bcdefg" {
+		t.Fatalf("slice holds %q after parent grew", got)
+	}
+	if err := b.Truncate(4); err != nil {
+		t.Fatal(err)
+	}
+	if s.Size() != 3 || s.String() != "bcd" {
+		t.Fatalf("slice holds %q after parent shrank", s.String())
+	}
+	far := b.Slice(10, 2)
+	if far.Size() != 0 {
+		t.Fatalf("Size of window past the end = %d, want 0", far.Size())
+	}
+	if n, err := far.WriteAt([]byte("zz"), 0); n != 2 || err != nil {
+		t.Fatalf("WriteAt into window past the end = %d, %v", n, err)
+	}
+	if b.Size() != 12 || far.String() != "zz" {
+		t.Fatalf("parent length %d, slice %q", b.Size(), far.String())
+	}
+}
+
+func TestSliceOfSlice(t *testing.T) {
+	b := NewBuffer("slice", []byte("0123456789"))
+	inner := b.Slice(2, 6).Slice(1, 10)
+	if got := inner.String(); got != "34567" {
+		t.Fatalf("nested slice holds %q", got)
+	}
+	if _, err := inner.WriteAt([]byte("xxxxxx"), 0); !errors.Is(err, ErrSliceBounds) {
+		t.Fatalf("write past the outer window = %v, want ErrSliceBounds", err)
+	}
+	data, err := io.ReadAll(inner.NewReader())
+	if err != nil || string(data) != "34567" {
+		t.Fatalf("reader read %q, %v", data, err)
+	}
+}
+
+func TestSliceOperations(t *testing.T) {
+	b := NewBuffer("slice", []byte("xxhello worldxx"))
+	s := b.Slice(2, 11)
+	head, tail, err := s.Split(5)
+	if err != nil || head.String() != "hello" || tail.String() != " world" {
+		t.Fatalf("Split = %q, %q, %v", head.String(), tail.String(), err)
+	}
+
+	key := make([]byte, 16)
+	iv := make([]byte, 16)
+	if err := s.Encrypt(key, iv); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.Bytes(); string(got[:2]) != "xx" || string(got[13:]) != "xx" {
+		t.Fatalf("Encrypt touched bytes outside the window: %q", got)
+	}
+	if err := s.Decrypt(key, iv); err != nil || b.String() != "xxhello worldxx" {
+		t.Fatalf("Decrypt = %v, parent holds %q", err, b.String())
+	}
+
+	off := int64(2)
+	if uintptr(unsafe.Pointer(&b.Bytes()[off]))%2 != 0 {
+		off++
+	}
+	view, err := b.Slice(off, 4).AsUint16Slice()
+	if err != nil || len(view) != 2 || unsafe.Pointer(&view[0]) != unsafe.Pointer(&b.Bytes()[off]) {
+		t.Fatalf("AsUint16Slice = %v, %v", view, err)
+	}
+
+	e := b.Slice(13, 4)
+	e.Seek(2, io.SeekStart)
+	data, err := e.Extend(2)
+	if err != nil || len(data) != 2 {
+		t.Fatalf("Extend = %v, %v", data, err)
+	}
+	copy(data, "!!")
+	if got := b.String(); got != "xxhello worldxx!!" {
+		t.Fatalf("parent holds %q after Extend", got)
+	}
+	if _, err := e.Extend(1); !errors.Is(err, ErrSliceBounds) {
+		t.Fatalf("Extend past end of window = %v, want ErrSliceBounds", err)
+	}
+
+	if err := s.Truncate(2); err == nil {
+		t.Fatal("Truncate on a slice succeeded")
+	}
+	if err := s.Insert(0, []byte("a")); err == nil {
+		t.Fatal("Insert on a slice succeeded")
+	}
+	if b.String() != "xxhello worldxx!!" {
+		t.Fatalf("failed resize changed the parent to %q", b.String())
+	}
+}
+
+func TestSliceTrailerCRC32(t *testing.T) {
+	body := []byte("payload")
+	data := append([]byte("junk"), body...)
+	data = binary.LittleEndian.AppendUint32(data, crc32.ChecksumIEEE(body))
+	b := NewBuffer("slice", append(data, "more"...))
+	s := b.Slice(4, int64(len(body)+4))
+	s.ExpectTrailerCRC32()
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close = %v", err)
+	}
+	bad := NewBuffer("slice", data).Slice(0, int64(len(data)))
+	bad.ExpectTrailerCRC32()
+	if err := bad.Close(); !errors.Is(err, ErrChecksum) {
+		t.Fatalf("Close over the wrong window = %v, want ErrChecksum", err)
+	}
+}
@@ -0,0 +1,99 @@
+package crunchio
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// snapshotChunk is the size of the chunks a snapshot is stored in, chunks that
+// are unchanged since the previous snapshot are shared with it
+const snapshotChunk = 4096
+
+// SnapshotID identifies a snapshot taken with Snapshot
+type SnapshotID uint64
+
+// savepoint is the state captured by a snapshot
+type savepoint struct {
+	chunks [][]byte
+	length int64
+	offset int64
+}
+
+// Snapshot captures the contents and offset of the buffer, returning an ID
+// that Restore can later roll back to
+//
+// Contents are stored in chunks, and only the chunks that changed since the
+// previous snapshot take new memory. Snapshots are held until released with
+// ReleaseSnapshot.
+func (b *Buffer) Snapshot() SnapshotID {
+	if b == nil {
+		panic("SNAPSHOT: buffer is nil")
+	}
+	b.Lock()
+	defer b.Unlock()
+	data := b.snapshot()
+	var previous [][]byte
+	if last, ok := b.snapshots[b.lastSnapshot]; ok {
+		previous = last.chunks
+	}
+	sp := &savepoint{length: int64(len(data)), offset: b.offset}
+	for i := 0; i < len(data); i += snapshotChunk {
+		chunk := data[i:min(i+snapshotChunk, len(data))]
+		if n := i / snapshotChunk; n < len(previous) && bytes.Equal(previous[n], chunk) {
+			chunk = previous[n]
+		} else {
+			chunk = bytes.Clone(chunk)
+		}
+		sp.chunks = append(sp.chunks, chunk)
+	}
+	if b.snapshots == nil {
+		b.snapshots = make(map[SnapshotID]*savepoint)
+	}
+	b.snapshotSeq++
+	b.lastSnapshot = b.snapshotSeq
+	b.snapshots[b.lastSnapshot] = sp
+	return b.lastSnapshot
+}
+
+// Restore rolls the contents and offset of the buffer back to those captured
+// by the snapshot id, which stays available to restore again, on a reference
+// the contents are restored in the buffer that owns them
+func (b *Buffer) Restore(id SnapshotID) error {
+	if b == nil {
+		panic("RESTORE: buffer is nil")
+	}
+	b.Lock()
+	defer b.Unlock()
+	if b.Closed() {
+		return fmt.Errorf("buffer: restore: %w", ErrClosed)
+	}
+	if b.readOnly {
+		return fmt.Errorf("buffer: restore: %w", ErrReadOnly)
+	}
+	sp, ok := b.snapshots[id]
+	if !ok {
+		return fmt.Errorf("buffer: restore: unknown snapshot %d", id)
+	}
+	b.unshare()
+	data := make([]byte, 0, sp.length)
+	for _, chunk := range sp.chunks {
+		data = append(data, chunk...)
+	}
+	if err := b.setSharedContents(data); err != nil {
+		return fmt.Errorf("buffer: restore: %w", err)
+	}
+	b.offset = sp.offset
+	b.bit = 0
+	return nil
+}
+
+// ReleaseSnapshot discards the snapshot id, freeing any chunks no other
+// snapshot shares
+func (b *Buffer) ReleaseSnapshot(id SnapshotID) {
+	if b == nil {
+		panic("RELEASESNAPSHOT: buffer is nil")
+	}
+	b.Lock()
+	defer b.Unlock()
+	delete(b.snapshots, id)
+}
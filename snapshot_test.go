@@ -0,0 +1,93 @@
+package crunchio
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	b := NewBuffer("snapshot", []byte("header"))
+	b.Seek(0, io.SeekEnd)
+	id := b.Snapshot()
+	b.Write([]byte(" and a failed parse"))
+	b.WriteAt([]byte("H"), 0)
+	if err := b.Restore(id); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != "header" || b.Tell() != 6 {
+		t.Fatalf("Restore left %q at offset %d", got, b.Tell())
+	}
+	b.Write([]byte("!"))
+	if err := b.Restore(id); err != nil || b.String() != "header" {
+		t.Fatalf("second Restore = %v, left %q", err, b.String())
+	}
+	b.ReleaseSnapshot(id)
+	if err := b.Restore(id); err == nil {
+		t.Fatal("Restore of a released snapshot succeeded")
+	}
+}
+
+func TestSnapshotSharesChunks(t *testing.T) {
+	data := bytes.Repeat([]byte{1}, 3*snapshotChunk)
+	b := NewBuffer("snapshot", data)
+	first := b.Snapshot()
+	b.WriteAt([]byte{2}, snapshotChunk)
+	second := b.Snapshot()
+	a, c := b.snapshots[first].chunks, b.snapshots[second].chunks
+	if &a[0][0] != &c[0][0] || &a[2][0] != &c[2][0] {
+		t.Fatal("unchanged chunks were copied")
+	}
+	if &a[1][0] == &c[1][0] {
+		t.Fatal("changed chunk was shared")
+	}
+	if err := b.Restore(first); err != nil || !bytes.Equal(b.Bytes(), data) {
+		t.Fatalf("Restore = %v", err)
+	}
+}
+
+func TestSnapshotReference(t *testing.T) {
+	b := NewBuffer("snapshot", []byte("shared"))
+	ref := b.Reference()
+	id := ref.Snapshot()
+	ref.Write([]byte("SHARED bytes"))
+	if err := ref.Restore(id); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != "shared" || ref.Size() != 6 {
+		t.Fatalf("Restore through a reference left the parent holding %q", got)
+	}
+}
+
+func TestSnapshotReferenceCOW(t *testing.T) {
+	b := NewBuffer("snapshot", []byte("shared"))
+	cow := b.ReferenceCOW()
+	id := cow.Snapshot()
+	b.WriteAt([]byte("S"), 0)
+	if err := cow.Restore(id); err != nil {
+		t.Fatal(err)
+	}
+	if cow.String() != "shared" || b.String() != "Shared" {
+		t.Fatalf("Restore on a COW reference left %q and its source %q", cow.String(), b.String())
+	}
+}
+
+func TestSnapshotSlice(t *testing.T) {
+	b := NewBuffer("snapshot", []byte("[abcd]"))
+	s := b.Slice(1, 4)
+	id := s.Snapshot()
+	s.Write([]byte("wxyz"))
+	if err := s.Restore(id); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != "[abcd]" {
+		t.Fatalf("Restore on a slice left the parent holding %q", got)
+	}
+	short := NewBuffer("snapshot", []byte("ab"))
+	window := short.Slice(0, 4)
+	empty := window.Snapshot()
+	short.Write([]byte("abcd"))
+	if err := window.Restore(empty); err == nil {
+		t.Fatal("Restore that would shrink a slice succeeded")
+	}
+}
@@ -0,0 +1,48 @@
+package crunchio
+
+// signatures lists the recognized file signatures and their format labels,
+// with longer and more specific signatures ahead of those they share a prefix
+// with
+var signatures = []struct {
+	format string
+	offset int
+	magic  []byte
+}{
+	{"png", 0, []byte("\x89PNG\r\n\x1a\n")},
+	{"gzip", 0, []byte{0x1F, 0x8B}},
+	{"zip", 0, []byte("PK\x03\x04")},
+	{"zip", 0, []byte("PK\x05\x06")},
+	{"elf", 0, []byte("\x7FELF")},
+	{"pdf", 0, []byte("%PDF-")},
+	{"gif", 0, []byte("GIF87a")},
+	{"gif", 0, []byte("GIF89a")},
+	{"jpeg", 0, []byte{0xFF, 0xD8, 0xFF}},
+	{"bzip2", 0, []byte("BZh")},
+	{"xz", 0, []byte("\xFD7zXZ\x00")},
+	{"zstd", 0, []byte{0x28, 0xB5, 0x2F, 0xFD}},
+	{"7z", 0, []byte("7z\xBC\xAF\x27\x1C")},
+	{"wasm", 0, []byte("\x00asm")},
+	{"pe", 0, []byte("MZ")},
+	{"tar", 257, []byte("ustar")},
+	{"crunchio", 0, []byte(saveMagic)},
+}
+
+// sniffLen is the number of leading bytes Sniff inspects
+const sniffLen = 262
+
+// Sniff inspects the start of the buffer for a known file signature, returning
+// its format label (such as "gzip", "png", "zip" or "elf") or "" if unknown
+func (b *Buffer) Sniff() string {
+	if b == nil {
+		panic("SNIFF: buffer is nil")
+	}
+	var head [sniffLen]byte
+	read, _ := b.readOffset(head[:], 0)
+	for _, sig := range signatures {
+		end := sig.offset + len(sig.magic)
+		if read >= end && string(head[sig.offset:end]) == string(sig.magic) {
+			return sig.format
+		}
+	}
+	return ""
+}
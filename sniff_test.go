@@ -0,0 +1,62 @@
+package crunchio
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"image"
+	"image/png"
+	"io"
+	"testing"
+)
+
+func TestSniff(t *testing.T) {
+	encoded := map[string]func(io.Writer){
+		"gzip": func(w io.Writer) {
+			gz := gzip.NewWriter(w)
+			gz.Write([]byte("data"))
+			gz.Close()
+		},
+		"zip": func(w io.Writer) {
+			z := zip.NewWriter(w)
+			f, _ := z.Create("file")
+			f.Write([]byte("data"))
+			z.Close()
+		},
+		"png": func(w io.Writer) {
+			png.Encode(w, image.NewGray(image.Rect(0, 0, 1, 1)))
+		},
+		"tar": func(w io.Writer) {
+			tw := tar.NewWriter(w)
+			tw.WriteHeader(&tar.Header{Name: "file", Mode: 0o644, Size: 4})
+			tw.Write([]byte("data"))
+			tw.Close()
+		},
+		"crunchio": func(w io.Writer) {
+			NewBuffer("saved", []byte("data")).Save(w)
+		},
+	}
+	for format, encode := range encoded {
+		b := NewBuffer("sniff")
+		encode(b)
+		b.Seek(1, io.SeekStart)
+		if got := b.Sniff(); got != format {
+			t.Fatalf("Sniff of a %s stream = %q", format, got)
+		}
+		if b.Tell() != 1 {
+			t.Fatalf("Sniff of a %s stream moved the offset to %d", format, b.Tell())
+		}
+	}
+	for data, format := range map[string]string{
+		"\x7FELF\x02\x01\x01": "elf",
+		"%PDF-1.7\n":          "pdf",
+		"MZ\x90\x00":          "pe",
+		"\x89PNG":             "",
+		"plain text":          "",
+		"":                    "",
+	} {
+		if got := NewBuffer("sniff", []byte(data)).Sniff(); got != format {
+			t.Fatalf("Sniff(%q) = %q, want %q", data, got, format)
+		}
+	}
+}
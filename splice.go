@@ -0,0 +1,119 @@
+package crunchio
+
+import (
+	"fmt"
+)
+
+// Insert writes p at off, shifting the bytes from off onwards up to make room
+// rather than overwriting them, offsets are left where they were
+func (b *Buffer) Insert(off int64, p []byte) error {
+	if b == nil {
+		panic("INSERT: buffer is nil")
+	}
+	if err := b.insert(off, p); err != nil {
+		return err
+	}
+	b.afterWrite(off, p)
+	return nil
+}
+
+func (b *Buffer) insert(off int64, p []byte) error {
+	b.Lock()
+	defer b.Unlock()
+	if b.Closed() {
+		return fmt.Errorf("buffer: insert: %w", ErrClosed)
+	}
+	if b.readOnly {
+		return fmt.Errorf("buffer: insert: %w", ErrReadOnly)
+	}
+	b.unshare()
+	if b.parent != nil {
+		if err := b.windowResize("insert"); err != nil {
+			return err
+		}
+		return b.parent.insert(off, p)
+	}
+	buffer := b.Buffer()
+	if buffer == nil && b.file == nil {
+		return fmt.Errorf("buffer: insert: crunch buffer vanished")
+	}
+	if off < 0 || off > b.length {
+		return fmt.Errorf("buffer: insert: offset %d out of range [0, %d]", off, b.length)
+	}
+	if len(p) == 0 {
+		return nil
+	}
+	if err := b.checkLimit("insert", b.length+int64(len(p))); err != nil {
+		return err
+	}
+	tail := make([]byte, b.length-off)
+	if err := b.readExactAtLocked(tail, off); err != nil {
+		return fmt.Errorf("buffer: insert: %w", err)
+	}
+	if _, err := b.writeOffsetLocked(append(append(make([]byte, 0, len(p)+len(tail)), p...), tail...), off); err != nil {
+		return fmt.Errorf("buffer: insert: %w", err)
+	}
+	b.hash(off, p)
+	return nil
+}
+
+// Delete removes the n bytes at off, shifting the bytes after them down to
+// close the gap, offsets past the new end are pulled back to it
+func (b *Buffer) Delete(off, n int64) error {
+	if b == nil {
+		panic("DELETE: buffer is nil")
+	}
+	b.Lock()
+	defer b.Unlock()
+	if b.Closed() {
+		return fmt.Errorf("buffer: delete: %w", ErrClosed)
+	}
+	if b.readOnly {
+		return fmt.Errorf("buffer: delete: %w", ErrReadOnly)
+	}
+	b.unshare()
+	if b.parent != nil {
+		if err := b.windowResize("delete"); err != nil {
+			return err
+		}
+		if err := b.parent.Delete(off, n); err != nil {
+			return err
+		}
+		b.clampTruncated()
+		return nil
+	}
+	buffer := b.Buffer()
+	if buffer == nil && b.file == nil {
+		return fmt.Errorf("buffer: delete: crunch buffer vanished")
+	}
+	if off < 0 || n < 0 || off+n > b.length {
+		return fmt.Errorf("buffer: delete: range [%d, %d) out of bounds for length %d", off, off+n, b.length)
+	}
+	if n == 0 {
+		return nil
+	}
+	tail := make([]byte, b.length-off-n)
+	if len(tail) > 0 {
+		if err := b.readExactAtLocked(tail, off+n); err != nil {
+			return fmt.Errorf("buffer: delete: %w", err)
+		}
+		if _, err := b.writeOffsetLocked(tail, off); err != nil {
+			return fmt.Errorf("buffer: delete: %w", err)
+		}
+	}
+	end := b.length - n
+	if b.file != nil {
+		if t, ok := b.file.(interface{ Truncate(int64) error }); ok {
+			if err := t.Truncate(end); err != nil {
+				return fmt.Errorf("buffer: delete: %w", err)
+			}
+		}
+	}
+	b.length = end
+	if b.offset > end {
+		b.offset = end
+		b.bit = 0
+	}
+	b.truncations.Add(1)
+	return nil
+}
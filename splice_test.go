@@ -0,0 +1,96 @@
+package crunchio
+
+import (
+	"io"
+	"testing"
+)
+
+func TestInsert(t *testing.T) {
+	b := NewBuffer("splice", []byte("cde"))
+	for _, step := range []struct {
+		off  int64
+		p    string
+		want string
+	}{
+		{0, "ab", "abcde"},
+		{3, "-", "abc-de"},
+		{6, "fg", "abc-defg"},
+		{2, "", "abc-defg"},
+	} {
+		if err := b.Insert(step.off, []byte(step.p)); err != nil {
+			t.Fatalf("Insert(%d, %q) = %v", step.off, step.p, err)
+		}
+		if got := b.String(); got != step.want {
+			t.Fatalf("Insert(%d, %q) left %q, want %q", step.off, step.p, got, step.want)
+		}
+	}
+	if b.Tell() != 0 {
+		t.Fatalf("Insert moved the offset to %d", b.Tell())
+	}
+	for _, off := range []int64{-1, 9} {
+		if err := b.Insert(off, []byte("x")); err == nil {
+			t.Fatalf("Insert(%d) out of range succeeded", off)
+		}
+	}
+	if got := b.String(); got != "abc-defg" {
+		t.Fatalf("failed Inserts left %q", got)
+	}
+
+	ref := b.Reference()
+	if err := ref.Insert(0, []byte(">")); err != nil || b.String() != ">abc-defg" {
+		t.Fatalf("Insert through a reference = %v, left %q", err, b.String())
+	}
+}
+
+func TestDelete(t *testing.T) {
+	b := NewBuffer("splice", []byte("abcdefgh"))
+	for _, step := range []struct {
+		off, n int64
+		want   string
+	}{
+		{0, 2, "cdefgh"},
+		{2, 1, "cdfgh"},
+		{3, 2, "cdf"},
+		{1, 0, "cdf"},
+	} {
+		if err := b.Delete(step.off, step.n); err != nil {
+			t.Fatalf("Delete(%d, %d) = %v", step.off, step.n, err)
+		}
+		if got := b.String(); got != step.want {
+			t.Fatalf("Delete(%d, %d) left %q, want %q", step.off, step.n, got, step.want)
+		}
+	}
+	for _, r := range [][2]int64{{-1, 1}, {0, -1}, {2, 2}, {4, 0}} {
+		if err := b.Delete(r[0], r[1]); err == nil {
+			t.Fatalf("Delete(%d, %d) out of range succeeded", r[0], r[1])
+		}
+	}
+	if got := b.String(); got != "cdf" {
+		t.Fatalf("failed Deletes left %q", got)
+	}
+}
+
+func TestDeleteClampsOffsets(t *testing.T) {
+	b := NewBuffer("splice", []byte("0123456789"))
+	ref := b.Reference()
+	ref.Seek(9, io.SeekStart)
+	near := b.Reference()
+	near.Seek(2, io.SeekStart)
+	b.Seek(8, io.SeekStart)
+	b.ReadBit()
+	if err := b.Delete(3, 5); err != nil {
+		t.Fatal(err)
+	}
+	if b.Tell() != 5 || b.TellBit() != 40 {
+		t.Fatalf("Delete left the offset at %d, bit %d, want 5, 40", b.Tell(), b.TellBit())
+	}
+	if ref.Tell() != 5 || near.Tell() != 2 {
+		t.Fatalf("Delete left references at %d and %d, want 5 and 2", ref.Tell(), near.Tell())
+	}
+	if err := ref.Delete(0, 3); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != "89" || ref.Tell() != 2 {
+		t.Fatalf("Delete through a reference left %q with the reference at %d", got, ref.Tell())
+	}
+}
@@ -0,0 +1,35 @@
+package crunchio
+
+import (
+	"fmt"
+	"io"
+	"unsafe"
+)
+
+// WriteString writes the bytes of s at the current offset without copying
+// them into an intermediate buffer, implementing io.StringWriter
+func (b *Buffer) WriteString(s string) (int, error) {
+	if b == nil {
+		panic("WRITESTRING: buffer is nil")
+	}
+	return b.Write(unsafe.Slice(unsafe.StringData(s), len(s)))
+}
+
+// ReadString reads exactly n bytes at the current offset as a string,
+// returning io.ErrUnexpectedEOF without advancing if fewer remain
+func (b *Buffer) ReadString(n int) (string, error) {
+	if b == nil {
+		panic("READSTRING: buffer is nil")
+	}
+	if n < 0 {
+		return "", fmt.Errorf("buffer: readstring: negative length %d", n)
+	}
+	data := make([]byte, n)
+	if err := b.readExact(data); err != nil {
+		if err == io.EOF && n > 0 {
+			err = io.ErrUnexpectedEOF
+		}
+		return "", err
+	}
+	return unsafe.String(unsafe.SliceData(data), n), nil
+}
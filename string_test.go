@@ -0,0 +1,70 @@
+package crunchio
+
+import (
+	"io"
+	"testing"
+)
+
+var _ io.StringWriter = (*Buffer)(nil)
+
+func TestWriteReadString(t *testing.T) {
+	b := NewBuffer("string", []byte("0123456789"))
+	b.Seek(2, io.SeekStart)
+	if n, err := b.WriteString("héllo"); n != 6 || err != nil {
+		t.Fatalf("WriteString = %d, %v", n, err)
+	}
+	if got := b.String(); got != "01héllo89" || b.Tell() != 8 {
+		t.Fatalf("WriteString left %q at offset %d", got, b.Tell())
+	}
+	b.Seek(2, io.SeekStart)
+	if s, err := b.ReadString(6); s != "héllo" || err != nil {
+		t.Fatalf("ReadString = %q, %v", s, err)
+	}
+	if s, err := b.ReadString(0); s != "" || err != nil {
+		t.Fatalf("ReadString(0) = %q, %v", s, err)
+	}
+	if _, err := b.ReadString(3); err != io.ErrUnexpectedEOF {
+		t.Fatalf("ReadString past the end = %v, want io.ErrUnexpectedEOF", err)
+	}
+	if b.Tell() != 8 {
+		t.Fatalf("failed ReadString moved the offset to %d", b.Tell())
+	}
+	if _, err := b.ReadString(-1); err == nil {
+		t.Fatal("ReadString(-1) succeeded")
+	}
+}
+
+func TestReadStringCopies(t *testing.T) {
+	b := NewBuffer("string", []byte("abc"))
+	s, _ := b.ReadString(3)
+	b.WriteAt([]byte("x"), 0)
+	if s != "abc" {
+		t.Fatalf("ReadString result changed to %q after a write", s)
+	}
+}
+
+func BenchmarkWriteString(b *testing.B) {
+	buffer := NewBuffer("string")
+	s := "the quick brown fox jumps over the lazy dog"
+	b.SetBytes(int64(len(s)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if i%256 == 0 {
+			buffer.Seek(0, io.SeekStart)
+		}
+		buffer.WriteString(s)
+	}
+}
+
+func BenchmarkWriteAbstractString(b *testing.B) {
+	buffer := NewBuffer("string")
+	var s any = "the quick brown fox jumps over the lazy dog"
+	b.SetBytes(int64(len(s.(string))))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if i%256 == 0 {
+			buffer.Seek(0, io.SeekStart)
+		}
+		buffer.WriteAbstract(s)
+	}
+}
@@ -0,0 +1,375 @@
+package crunchio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// fieldSpec describes how a single struct field is encoded, as parsed from a
+// `crunch:"..."` tag
+//
+// A tag is a comma-separated list holding at most one encoding (u8, u16, u32,
+// u64, i8, i16, i32, i64, f32, f64, bool or cstring) and an optional byte order
+// (le or be). Untagged fields fall back to their natural size in the buffer's
+// byte order, with int and uint encoded as 64 bits. Arrays and slices apply the
+// tag to each element, and slices are decoded using their current length. The
+// tag "-" skips a field. Encoding an integer that doesn't fit the width and
+// signedness of its tag is an error, as is decoding one that doesn't fit its
+// field.
+type fieldSpec struct {
+	kind  string
+	order binary.ByteOrder
+}
+
+var fieldKindSizes = map[string]int{
+	"u8": 1, "i8": 1, "bool": 1,
+	"u16": 2, "i16": 2,
+	"u32": 4, "i32": 4, "f32": 4,
+	"u64": 8, "i64": 8, "f64": 8,
+}
+
+func parseFieldTag(tag string, order binary.ByteOrder) (spec fieldSpec, err error) {
+	spec.order = order
+	for _, opt := range strings.Split(tag, ",") {
+		switch opt = strings.TrimSpace(opt); opt {
+		case "":
+		case "le":
+			spec.order = binary.LittleEndian
+		case "be":
+			spec.order = binary.BigEndian
+		case "cstring":
+			spec.kind = opt
+		default:
+			if _, ok := fieldKindSizes[opt]; !ok {
+				return spec, fmt.Errorf("unknown tag option %q", opt)
+			}
+			spec.kind = opt
+		}
+	}
+	return
+}
+
+func naturalFieldKind(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int8:
+		return "i8"
+	case reflect.Int16:
+		return "i16"
+	case reflect.Int32:
+		return "i32"
+	case reflect.Int, reflect.Int64:
+		return "i64"
+	case reflect.Uint8:
+		return "u8"
+	case reflect.Uint16:
+		return "u16"
+	case reflect.Uint32:
+		return "u32"
+	case reflect.Uint, reflect.Uint64:
+		return "u64"
+	case reflect.Float32:
+		return "f32"
+	case reflect.Float64:
+		return "f64"
+	}
+	return ""
+}
+
+// Marshal encodes the struct v at the current offset according to its crunch
+// struct tags
+func (b *Buffer) Marshal(v any) error {
+	if b == nil {
+		panic("MARSHAL: buffer is nil")
+	}
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("buffer: marshal: expected a struct, got %T", v)
+	}
+	data, err := appendStruct(nil, rv, b.byteOrder())
+	if err != nil {
+		return fmt.Errorf("buffer: marshal: %w", err)
+	}
+	_, err = b.Write(data)
+	return err
+}
+
+// Unmarshal decodes into the struct pointed to by v from the current offset
+// according to its crunch struct tags, restoring the offset on failure
+func (b *Buffer) Unmarshal(v any) error {
+	if b == nil {
+		panic("UNMARSHAL: buffer is nil")
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("buffer: unmarshal: expected a non-nil struct pointer, got %T", v)
+	}
+	start := b.Tell()
+	if err := b.readStruct(rv.Elem(), b.byteOrder()); err != nil {
+		b.Seek(start, io.SeekStart)
+		return fmt.Errorf("buffer: unmarshal: %w", err)
+	}
+	return nil
+}
+
+// WriteStruct encodes the struct v at the current offset, the same as Marshal
+func (b *Buffer) WriteStruct(v any) error {
+	if b == nil {
+		panic("WRITESTRUCT: buffer is nil")
+	}
+	return b.Marshal(v)
+}
+
+// ReadStruct decodes into the struct pointed to by v from the current offset,
+// the same as Unmarshal
+func (b *Buffer) ReadStruct(v any) error {
+	if b == nil {
+		panic("READSTRUCT: buffer is nil")
+	}
+	return b.Unmarshal(v)
+}
+
+func appendStruct(dst []byte, rv reflect.Value, order binary.ByteOrder) ([]byte, error) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tag := field.Tag.Get("crunch")
+		if tag == "-" || !field.IsExported() {
+			continue
+		}
+		spec, err := parseFieldTag(tag, order)
+		if err != nil {
+			return dst, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		if dst, err = appendField(dst, rv.Field(i), spec); err != nil {
+			return dst, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+	}
+	return dst, nil
+}
+
+func appendField(dst []byte, rv reflect.Value, spec fieldSpec) (_ []byte, err error) {
+	switch rv.Kind() {
+	case reflect.Struct:
+		return appendStruct(dst, rv, spec.order)
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < rv.Len(); i++ {
+			if dst, err = appendField(dst, rv.Index(i), spec); err != nil {
+				return dst, err
+			}
+		}
+		return dst, nil
+	case reflect.String:
+		if spec.kind != "cstring" {
+			return dst, fmt.Errorf("string fields require the cstring tag")
+		}
+		s := rv.String()
+		if strings.IndexByte(s, 0) >= 0 {
+			return dst, fmt.Errorf("cstring contains a NUL byte")
+		}
+		return append(append(dst, s...), 0), nil
+	}
+
+	kind := spec.kind
+	if kind == "" {
+		kind = naturalFieldKind(rv.Kind())
+	}
+	size, ok := fieldKindSizes[kind]
+	if !ok {
+		return dst, fmt.Errorf("cannot encode %s as %q", rv.Type(), kind)
+	}
+	isFloat := kind == "f32" || kind == "f64"
+	if isFloat != (rv.Kind() == reflect.Float32 || rv.Kind() == reflect.Float64) {
+		return dst, fmt.Errorf("cannot encode %s as %q", rv.Type(), kind)
+	}
+	var bits uint64
+	switch rv.Kind() {
+	case reflect.Bool:
+		if rv.Bool() {
+			bits = 1
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := rv.Int()
+		if !intFits(n, kind, size) {
+			return dst, fmt.Errorf("value %d overflows %q", n, kind)
+		}
+		bits = uint64(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n := rv.Uint()
+		if !uintFits(n, kind, size) {
+			return dst, fmt.Errorf("value %d overflows %q", n, kind)
+		}
+		bits = n
+	case reflect.Float32, reflect.Float64:
+		if kind == "f32" {
+			bits = uint64(math.Float32bits(float32(rv.Float())))
+		} else {
+			bits = math.Float64bits(rv.Float())
+		}
+	default:
+		return dst, fmt.Errorf("unsupported type %s", rv.Type())
+	}
+
+	var scratch [8]byte
+	switch size {
+	case 1:
+		scratch[0] = byte(bits)
+	case 2:
+		spec.order.PutUint16(scratch[:], uint16(bits))
+	case 4:
+		spec.order.PutUint32(scratch[:], uint32(bits))
+	case 8:
+		spec.order.PutUint64(scratch[:], bits)
+	}
+	return append(dst, scratch[:size]...), nil
+}
+
+func (b *Buffer) readStruct(rv reflect.Value, order binary.ByteOrder) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tag := field.Tag.Get("crunch")
+		if tag == "-" || !field.IsExported() {
+			continue
+		}
+		spec, err := parseFieldTag(tag, order)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		if err = b.readField(rv.Field(i), spec); err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+	}
+	return nil
+}
+
+func (b *Buffer) readField(rv reflect.Value, spec fieldSpec) error {
+	switch rv.Kind() {
+	case reflect.Struct:
+		return b.readStruct(rv, spec.order)
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < rv.Len(); i++ {
+			if err := b.readField(rv.Index(i), spec); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.String:
+		if spec.kind != "cstring" {
+			return fmt.Errorf("string fields require the cstring tag")
+		}
+		var s []byte
+		var c [1]byte
+		for {
+			if err := b.readExact(c[:]); err != nil {
+				if err == io.EOF {
+					err = io.ErrUnexpectedEOF
+				}
+				return err
+			}
+			if c[0] == 0 {
+				break
+			}
+			s = append(s, c[0])
+		}
+		rv.SetString(string(s))
+		return nil
+	}
+
+	kind := spec.kind
+	if kind == "" {
+		kind = naturalFieldKind(rv.Kind())
+	}
+	size, ok := fieldKindSizes[kind]
+	if !ok {
+		return fmt.Errorf("cannot decode %s as %q", rv.Type(), kind)
+	}
+	isFloat := kind == "f32" || kind == "f64"
+	if isFloat != (rv.Kind() == reflect.Float32 || rv.Kind() == reflect.Float64) {
+		return fmt.Errorf("cannot decode %s as %q", rv.Type(), kind)
+	}
+
+	var scratch [8]byte
+	if err := b.readExact(scratch[:size]); err != nil {
+		return err
+	}
+	var bits uint64
+	switch size {
+	case 1:
+		bits = uint64(scratch[0])
+	case 2:
+		bits = uint64(spec.order.Uint16(scratch[:]))
+	case 4:
+		bits = uint64(spec.order.Uint32(scratch[:]))
+	case 8:
+		bits = spec.order.Uint64(scratch[:])
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		rv.SetBool(bits != 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := signExtend(bits, kind)
+		if rv.OverflowInt(n) {
+			return fmt.Errorf("value %d overflows %s", n, rv.Type())
+		}
+		rv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n := uint64(signExtend(bits, kind))
+		if rv.OverflowUint(n) {
+			return fmt.Errorf("value %d overflows %s", n, rv.Type())
+		}
+		rv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		if kind == "f32" {
+			rv.SetFloat(float64(math.Float32frombits(uint32(bits))))
+		} else {
+			rv.SetFloat(math.Float64frombits(bits))
+		}
+	default:
+		return fmt.Errorf("unsupported type %s", rv.Type())
+	}
+	return nil
+}
+
+// intFits reports whether n can be encoded as the size byte kind and decoded
+// back unchanged, bool taking only 0 and 1
+func intFits(n int64, kind string, size int) bool {
+	width := uint(size) * 8
+	switch {
+	case kind == "bool":
+		return n == 0 || n == 1
+	case kind[0] == 'i':
+		return width == 64 || n >= -1<<(width-1) && n < 1<<(width-1)
+	}
+	return n >= 0 && (width == 64 || n < 1<<width)
+}
+
+// uintFits is intFits for unsigned values
+func uintFits(n uint64, kind string, size int) bool {
+	switch {
+	case kind == "bool":
+		return n <= 1
+	case kind[0] == 'i':
+		return n <= math.MaxInt64>>(64-uint(size)*8)
+	}
+	return size == 8 || n < 1<<(uint(size)*8)
+}
+
+// signExtend widens bits decoded as kind to an int64
+func signExtend(bits uint64, kind string) int64 {
+	switch kind {
+	case "i8":
+		return int64(int8(bits))
+	case "i16":
+		return int64(int16(bits))
+	case "i32":
+		return int64(int32(bits))
+	}
+	return int64(bits)
+}
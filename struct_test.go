@@ -0,0 +1,124 @@
+package crunchio
+
+import (
+	"io"
+	"slices"
+	"testing"
+)
+
+type taggedHeader struct {
+	Magic   uint32 `crunch:"u32,be"`
+	Version uint16 `crunch:"u16,le"`
+	Name    string `crunch:"cstring"`
+	Flags   int    `crunch:"i8"`
+	Size    int64
+	Skipped int `crunch:"-"`
+}
+
+func TestMarshalUnmarshal(t *testing.T) {
+	in := taggedHeader{Magic: 0xCAFEBABE, Version: 0x0102, Name: "crunch", Flags: -3, Size: 1 << 40, Skipped: 7}
+	b := NewBuffer("struct")
+	if err := b.Marshal(in); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{0xCA, 0xFE, 0xBA, 0xBE, 0x02, 0x01, 'c', 'r', 'u', 'n', 'c', 'h', 0, 0xFD, 0, 0, 0, 0, 0, 1, 0, 0}
+	if got := b.Bytes(); !slices.Equal(got, want) {
+		t.Fatalf("Marshal wrote % x, want % x", got, want)
+	}
+	b.Seek(0, io.SeekStart)
+	var out taggedHeader
+	if err := b.Unmarshal(&out); err != nil {
+		t.Fatal(err)
+	}
+	in.Skipped = 0
+	if out != in {
+		t.Fatalf("Unmarshal = %+v, want %+v", out, in)
+	}
+}
+
+func TestMarshalOverflow(t *testing.T) {
+	for _, v := range []any{
+		struct {
+			V int `crunch:"u8"`
+		}{300},
+		struct {
+			V int `crunch:"u16"`
+		}{-1},
+		struct {
+			V int16 `crunch:"i8"`
+		}{128},
+		struct {
+			V uint64 `crunch:"i64"`
+		}{1 << 63},
+		struct {
+			V int `crunch:"bool"`
+		}{2},
+	} {
+		b := NewBuffer("struct")
+		if err := b.Marshal(v); err == nil {
+			t.Fatalf("Marshal(%+v) succeeded, wrote % x", v, b.Bytes())
+		}
+		if b.Size() != 0 {
+			t.Fatalf("failed Marshal(%+v) wrote % x", v, b.Bytes())
+		}
+	}
+	b := NewBuffer("struct")
+	if err := b.Marshal(struct {
+		A int    `crunch:"u8"`
+		B int    `crunch:"i8"`
+		C uint32 `crunch:"i32"`
+	}{255, -128, 1<<31 - 1}); err != nil {
+		t.Fatalf("Marshal at the edges of the tags = %v", err)
+	}
+}
+
+func TestUnmarshalShort(t *testing.T) {
+	b := NewBuffer("struct", []byte{0xCA, 0xFE, 0xBA, 0xBE, 0x02, 0x01, 'a'})
+	var out taggedHeader
+	if err := b.Unmarshal(&out); err == nil {
+		t.Fatal("Unmarshal of a truncated cstring succeeded")
+	}
+	if b.Tell() != 0 {
+		t.Fatalf("offset left at %d after a failed Unmarshal", b.Tell())
+	}
+}
+
+type packet struct {
+	Header struct {
+		Magic  [2]byte
+		Length uint16
+	} `crunch:"be"`
+	Ratio   float32
+	Ack     bool
+	Samples []int16 `crunch:"i16,le"`
+	Name    string  `crunch:"cstring"`
+}
+
+func TestWriteReadStruct(t *testing.T) {
+	in := packet{Ratio: 1.5, Ack: true, Samples: []int16{-2, 0x0102}, Name: "ab"}
+	in.Header.Magic = [2]byte{'P', 'K'}
+	in.Header.Length = 5
+	b := NewBuffer("struct", []byte("pre"))
+	b.Seek(0, io.SeekEnd)
+	if err := b.WriteStruct(in); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{'p', 'r', 'e', 'P', 'K', 0, 5, 0, 0, 0xC0, 0x3F, 1, 0xFE, 0xFF, 0x02, 0x01, 'a', 'b', 0}
+	if got := b.Bytes(); !slices.Equal(got, want) || b.Tell() != int64(len(want)) {
+		t.Fatalf("WriteStruct left % x at offset %d, want % x", got, b.Tell(), want)
+	}
+	b.Seek(3, io.SeekStart)
+	out := packet{Samples: make([]int16, 2)}
+	if err := b.ReadStruct(&out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Header != in.Header || out.Ratio != in.Ratio || !out.Ack || !slices.Equal(out.Samples, in.Samples) || out.Name != in.Name {
+		t.Fatalf("ReadStruct = %+v, want %+v", out, in)
+	}
+	if err := b.ReadStruct(out); err == nil {
+		t.Fatal("ReadStruct into a struct value succeeded")
+	}
+	if err := b.WriteStruct(42); err == nil {
+		t.Fatal("WriteStruct of an int succeeded")
+	}
+}
@@ -0,0 +1,18 @@
+package crunchio
+
+// Tracer opens spans around buffer operations, StartSpan returns the function
+// that ends the span
+type Tracer interface {
+	StartSpan(name string) func()
+}
+
+// SetTracer makes Read, Write and WriteAbstract open a span named after the
+// operation and buffer, or disables tracing when t is nil
+//
+// Like SetName, it must not be called concurrently with other operations.
+func (b *Buffer) SetTracer(t Tracer) {
+	if b == nil {
+		panic("SETTRACER: buffer is nil")
+	}
+	b.tracer = t
+}
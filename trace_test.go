@@ -0,0 +1,49 @@
+package crunchio
+
+import (
+	"bytes"
+	"io"
+	"slices"
+	"testing"
+)
+
+// fakeTracer records every span it opens and closes
+type fakeTracer struct {
+	events []string
+}
+
+func (f *fakeTracer) StartSpan(name string) func() {
+	f.events = append(f.events, "start "+name)
+	return func() {
+		f.events = append(f.events, "end "+name)
+	}
+}
+
+func TestTracer(t *testing.T) {
+	tracer := &fakeTracer{}
+	b := NewBuffer("trace")
+	b.SetName("traced")
+	b.SetTracer(tracer)
+	b.Write([]byte("data"))
+	b.WriteAbstract(uint16(1))
+	b.Seek(0, io.SeekStart)
+	b.Read(make([]byte, 2))
+	b.WriteTo(io.Discard)
+	b.ReadFrom(bytes.NewReader([]byte("more")))
+	// WriteAbstract and ReadFrom write through Write, so its span nests inside theirs
+	want := []string{
+		"start Write traced", "end Write traced",
+		"start WriteAbstract traced", "start Write traced", "end Write traced", "end WriteAbstract traced",
+		"start Read traced", "end Read traced",
+		"start WriteTo traced", "end WriteTo traced",
+		"start ReadFrom traced", "start Write traced", "end Write traced", "end ReadFrom traced",
+	}
+	if !slices.Equal(tracer.events, want) {
+		t.Fatalf("spans = %q, want %q", tracer.events, want)
+	}
+	b.SetTracer(nil)
+	b.Write([]byte("untraced"))
+	if len(tracer.events) != len(want) {
+		t.Fatalf("disabled tracer saw %q", tracer.events[len(want):])
+	}
+}
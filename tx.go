@@ -0,0 +1,120 @@
+package crunchio
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrTxDone is returned when committing or rolling back a transaction that has
+// already been committed or rolled back
+var ErrTxDone = errors.New("transaction already finished")
+
+// ErrTxConflict is returned when committing a transaction whose buffer was
+// changed directly since it began
+var ErrTxConflict = errors.New("buffer changed since the transaction began")
+
+// Tx is a transaction over a buffer, a private view of its bytes whose writes
+// are staged until Commit applies them to the buffer or Rollback discards them
+//
+// The embedded Buffer is used like any other until the transaction finishes,
+// after which it is closed.
+type Tx struct {
+	*Buffer
+	target     *Buffer
+	generation uint64
+	done       bool
+}
+
+// Begin starts a transaction over b, positioned at b's current offset
+func (b *Buffer) Begin() *Tx {
+	if b == nil {
+		panic("BEGIN: buffer is nil")
+	}
+	staged := b.ReferenceCOW()
+	staged.offset = b.Tell()
+	return &Tx{Buffer: staged, target: b, generation: b.root().generation()}
+}
+
+// generation counts the changes made to the bytes of a root
+func (b *Buffer) generation() uint64 {
+	return b.truncations.Load() + b.writes.Load()
+}
+
+// Commit replaces the contents of the buffer with the staged ones and moves its
+// offset to the transaction's, failing with ErrTxConflict and leaving the
+// transaction open if the buffer's bytes were changed directly since Begin
+func (tx *Tx) Commit() error {
+	if tx == nil {
+		panic("COMMIT: transaction is nil")
+	}
+	if tx.done {
+		return fmt.Errorf("buffer: commit: %w", ErrTxDone)
+	}
+	tx.Lock()
+	written := !tx.cow
+	offset := tx.offset
+	var data []byte
+	if written {
+		data = tx.snapshot()
+	}
+	tx.Unlock()
+	if err := tx.target.apply(data, written, offset, tx.generation); err != nil {
+		return err
+	}
+	tx.finish()
+	if written {
+		tx.target.afterWrite(0, data)
+	}
+	return nil
+}
+
+// apply installs the contents staged by a transaction when it wrote any and
+// moves the offset, unless the bytes changed since the transaction's generation
+func (b *Buffer) apply(data []byte, written bool, offset int64, generation uint64) error {
+	b.Lock()
+	defer b.Unlock()
+	if b.Closed() {
+		return fmt.Errorf("buffer: commit: %w", ErrClosed)
+	}
+	if b.root().generation() != generation {
+		return fmt.Errorf("buffer: commit: %w", ErrTxConflict)
+	}
+	if written {
+		if b.readOnly {
+			return fmt.Errorf("buffer: commit: %w", ErrReadOnly)
+		}
+		b.unshare()
+		if err := b.setSharedContents(data); err != nil {
+			return fmt.Errorf("buffer: commit: %w", err)
+		}
+		b.hash(0, data)
+	}
+	b.offset = offset
+	b.bit = 0
+	return nil
+}
+
+// Rollback discards the staged writes, leaving the buffer as it was
+func (tx *Tx) Rollback() error {
+	if tx == nil {
+		panic("ROLLBACK: transaction is nil")
+	}
+	if tx.done {
+		return fmt.Errorf("buffer: rollback: %w", ErrTxDone)
+	}
+	tx.finish()
+	return nil
+}
+
+// finish closes the staged view without touching the buffer it was taken from
+func (tx *Tx) finish() {
+	tx.done = true
+	tx.Lock()
+	defer tx.Unlock()
+	tx.parent = nil
+	tx.buffer = nil
+	tx.length = 0
+	tx.closed = true
+	tx.rootCache.Store(nil)
+	rootEpoch.Add(1)
+}
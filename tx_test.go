@@ -0,0 +1,97 @@
+package crunchio
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestTxCommit(t *testing.T) {
+	b := NewBuffer("tx", []byte("hello world"))
+	b.Seek(6, io.SeekStart)
+	tx := b.Begin()
+	if tx.Tell() != 6 {
+		t.Fatalf("transaction starts at %d, want 6", tx.Tell())
+	}
+	tx.Write([]byte("there"))
+	if got := b.String(); got != "hello world" {
+		t.Fatalf("staged write leaked into the buffer: %q", got)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != "hello there" || b.Tell() != 11 {
+		t.Fatalf("Commit left %q at offset %d", got, b.Tell())
+	}
+	if err := tx.Commit(); !errors.Is(err, ErrTxDone) {
+		t.Fatalf("second Commit = %v, want ErrTxDone", err)
+	}
+	if err := tx.Rollback(); !errors.Is(err, ErrTxDone) {
+		t.Fatalf("Rollback after Commit = %v, want ErrTxDone", err)
+	}
+}
+
+func TestTxCommitWithoutWrites(t *testing.T) {
+	b := NewBuffer("tx", []byte("abcdef"))
+	hooked := false
+	b.SetWriteHook(func(int64, []byte) { hooked = true })
+	tx := b.Begin()
+	tx.Seek(4, io.SeekStart)
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != "abcdef" || b.Tell() != 4 {
+		t.Fatalf("Commit without writes left %q at offset %d", got, b.Tell())
+	}
+	if hooked {
+		t.Fatal("Commit without writes called the write hook")
+	}
+}
+
+func TestTxRollback(t *testing.T) {
+	b := NewBuffer("tx", []byte("abcdef"))
+	tx := b.Begin()
+	tx.Write([]byte("XYZ"))
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != "abcdef" || b.Tell() != 0 {
+		t.Fatalf("Rollback left %q at offset %d", got, b.Tell())
+	}
+	if _, err := tx.Write([]byte("late")); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Write after Rollback = %v, want ErrClosed", err)
+	}
+	if err := tx.Commit(); !errors.Is(err, ErrTxDone) {
+		t.Fatalf("Commit after Rollback = %v, want ErrTxDone", err)
+	}
+	if got := b.String(); got != "abcdef" {
+		t.Fatalf("writes after Rollback reached the buffer: %q", got)
+	}
+}
+
+func TestTxConflict(t *testing.T) {
+	for name, change := range map[string]func(b *Buffer){
+		"write":    func(b *Buffer) { b.WriteAt([]byte("!"), 0) },
+		"truncate": func(b *Buffer) { b.Truncate(2) },
+		"append":   func(b *Buffer) { b.Merge(NewBuffer("more", []byte("gh"))) },
+		"fill":     func(b *Buffer) { b.Fill(0, 1, 'z') },
+		"reference": func(b *Buffer) {
+			b.Reference().Write([]byte("!"))
+		},
+	} {
+		b := NewBuffer("tx", []byte("abcdef"))
+		tx := b.Begin()
+		tx.Write([]byte("XYZ"))
+		change(b)
+		before := b.String()
+		if err := tx.Commit(); !errors.Is(err, ErrTxConflict) {
+			t.Fatalf("%s: Commit after a direct change = %v, want ErrTxConflict", name, err)
+		}
+		if got := b.String(); got != before {
+			t.Fatalf("%s: failed Commit changed the buffer to %q", name, got)
+		}
+		if err := tx.Rollback(); err != nil {
+			t.Fatalf("%s: Rollback after a failed Commit = %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,450 @@
+package crunchio
+
+import "encoding/binary"
+
+// The typed accessors read and write a single fixed-size value at the current
+// offset and advance past it. Names without an LE or BE suffix use the
+// buffer's byte order, and reads return io.ErrUnexpectedEOF without advancing
+// if too few bytes remain.
+
+// ReadU8 reads a uint8 at the current offset
+func (b *Buffer) ReadU8() (uint8, error) {
+	if b == nil {
+		panic("READU8: buffer is nil")
+	}
+	return readValue[uint8](b, b.byteOrder())
+}
+
+// WriteU8 writes a uint8 at the current offset
+func (b *Buffer) WriteU8(v uint8) error {
+	if b == nil {
+		panic("WRITEU8: buffer is nil")
+	}
+	_, err := writeValue(b, b.byteOrder(), v)
+	return err
+}
+
+// ReadI8 reads an int8 at the current offset
+func (b *Buffer) ReadI8() (int8, error) {
+	if b == nil {
+		panic("READI8: buffer is nil")
+	}
+	return readValue[int8](b, b.byteOrder())
+}
+
+// WriteI8 writes an int8 at the current offset
+func (b *Buffer) WriteI8(v int8) error {
+	if b == nil {
+		panic("WRITEI8: buffer is nil")
+	}
+	_, err := writeValue(b, b.byteOrder(), v)
+	return err
+}
+
+// ReadU16 reads a uint16 at the current offset
+func (b *Buffer) ReadU16() (uint16, error) {
+	if b == nil {
+		panic("READU16: buffer is nil")
+	}
+	return readValue[uint16](b, b.byteOrder())
+}
+
+// WriteU16 writes a uint16 at the current offset
+func (b *Buffer) WriteU16(v uint16) error {
+	if b == nil {
+		panic("WRITEU16: buffer is nil")
+	}
+	_, err := writeValue(b, b.byteOrder(), v)
+	return err
+}
+
+// ReadU16LE reads a little-endian uint16 at the current offset
+func (b *Buffer) ReadU16LE() (uint16, error) {
+	if b == nil {
+		panic("READU16LE: buffer is nil")
+	}
+	return readValue[uint16](b, binary.LittleEndian)
+}
+
+// WriteU16LE writes a little-endian uint16 at the current offset
+func (b *Buffer) WriteU16LE(v uint16) error {
+	if b == nil {
+		panic("WRITEU16LE: buffer is nil")
+	}
+	_, err := writeValue(b, binary.LittleEndian, v)
+	return err
+}
+
+// ReadU16BE reads a big-endian uint16 at the current offset
+func (b *Buffer) ReadU16BE() (uint16, error) {
+	if b == nil {
+		panic("READU16BE: buffer is nil")
+	}
+	return readValue[uint16](b, binary.BigEndian)
+}
+
+// WriteU16BE writes a big-endian uint16 at the current offset
+func (b *Buffer) WriteU16BE(v uint16) error {
+	if b == nil {
+		panic("WRITEU16BE: buffer is nil")
+	}
+	_, err := writeValue(b, binary.BigEndian, v)
+	return err
+}
+
+// ReadI16 reads an int16 at the current offset
+func (b *Buffer) ReadI16() (int16, error) {
+	if b == nil {
+		panic("READI16: buffer is nil")
+	}
+	return readValue[int16](b, b.byteOrder())
+}
+
+// WriteI16 writes an int16 at the current offset
+func (b *Buffer) WriteI16(v int16) error {
+	if b == nil {
+		panic("WRITEI16: buffer is nil")
+	}
+	_, err := writeValue(b, b.byteOrder(), v)
+	return err
+}
+
+// ReadI16LE reads a little-endian int16 at the current offset
+func (b *Buffer) ReadI16LE() (int16, error) {
+	if b == nil {
+		panic("READI16LE: buffer is nil")
+	}
+	return readValue[int16](b, binary.LittleEndian)
+}
+
+// WriteI16LE writes a little-endian int16 at the current offset
+func (b *Buffer) WriteI16LE(v int16) error {
+	if b == nil {
+		panic("WRITEI16LE: buffer is nil")
+	}
+	_, err := writeValue(b, binary.LittleEndian, v)
+	return err
+}
+
+// ReadI16BE reads a big-endian int16 at the current offset
+func (b *Buffer) ReadI16BE() (int16, error) {
+	if b == nil {
+		panic("READI16BE: buffer is nil")
+	}
+	return readValue[int16](b, binary.BigEndian)
+}
+
+// WriteI16BE writes a big-endian int16 at the current offset
+func (b *Buffer) WriteI16BE(v int16) error {
+	if b == nil {
+		panic("WRITEI16BE: buffer is nil")
+	}
+	_, err := writeValue(b, binary.BigEndian, v)
+	return err
+}
+
+// ReadU32 reads a uint32 at the current offset
+func (b *Buffer) ReadU32() (uint32, error) {
+	if b == nil {
+		panic("READU32: buffer is nil")
+	}
+	return readValue[uint32](b, b.byteOrder())
+}
+
+// WriteU32 writes a uint32 at the current offset
+func (b *Buffer) WriteU32(v uint32) error {
+	if b == nil {
+		panic("WRITEU32: buffer is nil")
+	}
+	_, err := writeValue(b, b.byteOrder(), v)
+	return err
+}
+
+// ReadU32LE reads a little-endian uint32 at the current offset
+func (b *Buffer) ReadU32LE() (uint32, error) {
+	if b == nil {
+		panic("READU32LE: buffer is nil")
+	}
+	return readValue[uint32](b, binary.LittleEndian)
+}
+
+// WriteU32LE writes a little-endian uint32 at the current offset
+func (b *Buffer) WriteU32LE(v uint32) error {
+	if b == nil {
+		panic("WRITEU32LE: buffer is nil")
+	}
+	_, err := writeValue(b, binary.LittleEndian, v)
+	return err
+}
+
+// ReadU32BE reads a big-endian uint32 at the current offset
+func (b *Buffer) ReadU32BE() (uint32, error) {
+	if b == nil {
+		panic("READU32BE: buffer is nil")
+	}
+	return readValue[uint32](b, binary.BigEndian)
+}
+
+// WriteU32BE writes a big-endian uint32 at the current offset
+func (b *Buffer) WriteU32BE(v uint32) error {
+	if b == nil {
+		panic("WRITEU32BE: buffer is nil")
+	}
+	_, err := writeValue(b, binary.BigEndian, v)
+	return err
+}
+
+// ReadI32 reads an int32 at the current offset
+func (b *Buffer) ReadI32() (int32, error) {
+	if b == nil {
+		panic("READI32: buffer is nil")
+	}
+	return readValue[int32](b, b.byteOrder())
+}
+
+// WriteI32 writes an int32 at the current offset
+func (b *Buffer) WriteI32(v int32) error {
+	if b == nil {
+		panic("WRITEI32: buffer is nil")
+	}
+	_, err := writeValue(b, b.byteOrder(), v)
+	return err
+}
+
+// ReadI32LE reads a little-endian int32 at the current offset
+func (b *Buffer) ReadI32LE() (int32, error) {
+	if b == nil {
+		panic("READI32LE: buffer is nil")
+	}
+	return readValue[int32](b, binary.LittleEndian)
+}
+
+// WriteI32LE writes a little-endian int32 at the current offset
+func (b *Buffer) WriteI32LE(v int32) error {
+	if b == nil {
+		panic("WRITEI32LE: buffer is nil")
+	}
+	_, err := writeValue(b, binary.LittleEndian, v)
+	return err
+}
+
+// ReadI32BE reads a big-endian int32 at the current offset
+func (b *Buffer) ReadI32BE() (int32, error) {
+	if b == nil {
+		panic("READI32BE: buffer is nil")
+	}
+	return readValue[int32](b, binary.BigEndian)
+}
+
+// WriteI32BE writes a big-endian int32 at the current offset
+func (b *Buffer) WriteI32BE(v int32) error {
+	if b == nil {
+		panic("WRITEI32BE: buffer is nil")
+	}
+	_, err := writeValue(b, binary.BigEndian, v)
+	return err
+}
+
+// ReadU64 reads a uint64 at the current offset
+func (b *Buffer) ReadU64() (uint64, error) {
+	if b == nil {
+		panic("READU64: buffer is nil")
+	}
+	return readValue[uint64](b, b.byteOrder())
+}
+
+// WriteU64 writes a uint64 at the current offset
+func (b *Buffer) WriteU64(v uint64) error {
+	if b == nil {
+		panic("WRITEU64: buffer is nil")
+	}
+	_, err := writeValue(b, b.byteOrder(), v)
+	return err
+}
+
+// ReadU64LE reads a little-endian uint64 at the current offset
+func (b *Buffer) ReadU64LE() (uint64, error) {
+	if b == nil {
+		panic("READU64LE: buffer is nil")
+	}
+	return readValue[uint64](b, binary.LittleEndian)
+}
+
+// WriteU64LE writes a little-endian uint64 at the current offset
+func (b *Buffer) WriteU64LE(v uint64) error {
+	if b == nil {
+		panic("WRITEU64LE: buffer is nil")
+	}
+	_, err := writeValue(b, binary.LittleEndian, v)
+	return err
+}
+
+// ReadU64BE reads a big-endian uint64 at the current offset
+func (b *Buffer) ReadU64BE() (uint64, error) {
+	if b == nil {
+		panic("READU64BE: buffer is nil")
+	}
+	return readValue[uint64](b, binary.BigEndian)
+}
+
+// WriteU64BE writes a big-endian uint64 at the current offset
+func (b *Buffer) WriteU64BE(v uint64) error {
+	if b == nil {
+		panic("WRITEU64BE: buffer is nil")
+	}
+	_, err := writeValue(b, binary.BigEndian, v)
+	return err
+}
+
+// ReadI64 reads an int64 at the current offset
+func (b *Buffer) ReadI64() (int64, error) {
+	if b == nil {
+		panic("READI64: buffer is nil")
+	}
+	return readValue[int64](b, b.byteOrder())
+}
+
+// WriteI64 writes an int64 at the current offset
+func (b *Buffer) WriteI64(v int64) error {
+	if b == nil {
+		panic("WRITEI64: buffer is nil")
+	}
+	_, err := writeValue(b, b.byteOrder(), v)
+	return err
+}
+
+// ReadI64LE reads a little-endian int64 at the current offset
+func (b *Buffer) ReadI64LE() (int64, error) {
+	if b == nil {
+		panic("READI64LE: buffer is nil")
+	}
+	return readValue[int64](b, binary.LittleEndian)
+}
+
+// WriteI64LE writes a little-endian int64 at the current offset
+func (b *Buffer) WriteI64LE(v int64) error {
+	if b == nil {
+		panic("WRITEI64LE: buffer is nil")
+	}
+	_, err := writeValue(b, binary.LittleEndian, v)
+	return err
+}
+
+// ReadI64BE reads a big-endian int64 at the current offset
+func (b *Buffer) ReadI64BE() (int64, error) {
+	if b == nil {
+		panic("READI64BE: buffer is nil")
+	}
+	return readValue[int64](b, binary.BigEndian)
+}
+
+// WriteI64BE writes a big-endian int64 at the current offset
+func (b *Buffer) WriteI64BE(v int64) error {
+	if b == nil {
+		panic("WRITEI64BE: buffer is nil")
+	}
+	_, err := writeValue(b, binary.BigEndian, v)
+	return err
+}
+
+// ReadF32 reads a float32 at the current offset
+func (b *Buffer) ReadF32() (float32, error) {
+	if b == nil {
+		panic("READF32: buffer is nil")
+	}
+	return readValue[float32](b, b.byteOrder())
+}
+
+// WriteF32 writes a float32 at the current offset
+func (b *Buffer) WriteF32(v float32) error {
+	if b == nil {
+		panic("WRITEF32: buffer is nil")
+	}
+	_, err := writeValue(b, b.byteOrder(), v)
+	return err
+}
+
+// ReadF32LE reads a little-endian float32 at the current offset
+func (b *Buffer) ReadF32LE() (float32, error) {
+	if b == nil {
+		panic("READF32LE: buffer is nil")
+	}
+	return readValue[float32](b, binary.LittleEndian)
+}
+
+// WriteF32LE writes a little-endian float32 at the current offset
+func (b *Buffer) WriteF32LE(v float32) error {
+	if b == nil {
+		panic("WRITEF32LE: buffer is nil")
+	}
+	_, err := writeValue(b, binary.LittleEndian, v)
+	return err
+}
+
+// ReadF32BE reads a big-endian float32 at the current offset
+func (b *Buffer) ReadF32BE() (float32, error) {
+	if b == nil {
+		panic("READF32BE: buffer is nil")
+	}
+	return readValue[float32](b, binary.BigEndian)
+}
+
+// WriteF32BE writes a big-endian float32 at the current offset
+func (b *Buffer) WriteF32BE(v float32) error {
+	if b == nil {
+		panic("WRITEF32BE: buffer is nil")
+	}
+	_, err := writeValue(b, binary.BigEndian, v)
+	return err
+}
+
+// ReadF64 reads a float64 at the current offset
+func (b *Buffer) ReadF64() (float64, error) {
+	if b == nil {
+		panic("READF64: buffer is nil")
+	}
+	return readValue[float64](b, b.byteOrder())
+}
+
+// WriteF64 writes a float64 at the current offset
+func (b *Buffer) WriteF64(v float64) error {
+	if b == nil {
+		panic("WRITEF64: buffer is nil")
+	}
+	_, err := writeValue(b, b.byteOrder(), v)
+	return err
+}
+
+// ReadF64LE reads a little-endian float64 at the current offset
+func (b *Buffer) ReadF64LE() (float64, error) {
+	if b == nil {
+		panic("READF64LE: buffer is nil")
+	}
+	return readValue[float64](b, binary.LittleEndian)
+}
+
+// WriteF64LE writes a little-endian float64 at the current offset
+func (b *Buffer) WriteF64LE(v float64) error {
+	if b == nil {
+		panic("WRITEF64LE: buffer is nil")
+	}
+	_, err := writeValue(b, binary.LittleEndian, v)
+	return err
+}
+
+// ReadF64BE reads a big-endian float64 at the current offset
+func (b *Buffer) ReadF64BE() (float64, error) {
+	if b == nil {
+		panic("READF64BE: buffer is nil")
+	}
+	return readValue[float64](b, binary.BigEndian)
+}
+
+// WriteF64BE writes a big-endian float64 at the current offset
+func (b *Buffer) WriteF64BE(v float64) error {
+	if b == nil {
+		panic("WRITEF64BE: buffer is nil")
+	}
+	_, err := writeValue(b, binary.BigEndian, v)
+	return err
+}
@@ -0,0 +1,98 @@
+package crunchio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+)
+
+// typedTest is a round trip through one pair of typed accessors
+type typedTest struct {
+	name  string
+	order binary.ByteOrder
+	value any
+	write func(b *Buffer) error
+	read  func(b *Buffer) (any, error)
+}
+
+// typed builds a typedTest of v through write and read, a nil order meaning
+// the accessors use the buffer's byte order
+func typed[T Numeric](name string, order binary.ByteOrder, v T, write func(*Buffer, T) error, read func(*Buffer) (T, error)) typedTest {
+	return typedTest{
+		name:  name,
+		order: order,
+		value: v,
+		write: func(b *Buffer) error { return write(b, v) },
+		read: func(b *Buffer) (any, error) {
+			got, err := read(b)
+			return got, err
+		},
+	}
+}
+
+func TestTypedRoundTrip(t *testing.T) {
+	le, be := binary.LittleEndian, binary.BigEndian
+	tests := []typedTest{
+		typed("U8", nil, uint8(0xA5), (*Buffer).WriteU8, (*Buffer).ReadU8),
+		typed("I8", nil, int8(-0x5A), (*Buffer).WriteI8, (*Buffer).ReadI8),
+		typed("U16", nil, uint16(0xBEEF), (*Buffer).WriteU16, (*Buffer).ReadU16),
+		typed("U16LE", le, uint16(0xBEEF), (*Buffer).WriteU16LE, (*Buffer).ReadU16LE),
+		typed("U16BE", be, uint16(0xBEEF), (*Buffer).WriteU16BE, (*Buffer).ReadU16BE),
+		typed("I16", nil, int16(math.MinInt16+1), (*Buffer).WriteI16, (*Buffer).ReadI16),
+		typed("I16LE", le, int16(-2), (*Buffer).WriteI16LE, (*Buffer).ReadI16LE),
+		typed("I16BE", be, int16(-2), (*Buffer).WriteI16BE, (*Buffer).ReadI16BE),
+		typed("U32", nil, uint32(0xDEADBEEF), (*Buffer).WriteU32, (*Buffer).ReadU32),
+		typed("U32LE", le, uint32(0xDEADBEEF), (*Buffer).WriteU32LE, (*Buffer).ReadU32LE),
+		typed("U32BE", be, uint32(0xDEADBEEF), (*Buffer).WriteU32BE, (*Buffer).ReadU32BE),
+		typed("I32", nil, int32(math.MinInt32), (*Buffer).WriteI32, (*Buffer).ReadI32),
+		typed("I32LE", le, int32(-123456), (*Buffer).WriteI32LE, (*Buffer).ReadI32LE),
+		typed("I32BE", be, int32(-123456), (*Buffer).WriteI32BE, (*Buffer).ReadI32BE),
+		typed("U64", nil, uint64(0x0123456789ABCDEF), (*Buffer).WriteU64, (*Buffer).ReadU64),
+		typed("U64LE", le, uint64(math.MaxUint64), (*Buffer).WriteU64LE, (*Buffer).ReadU64LE),
+		typed("U64BE", be, uint64(0x0123456789ABCDEF), (*Buffer).WriteU64BE, (*Buffer).ReadU64BE),
+		typed("I64", nil, int64(math.MinInt64), (*Buffer).WriteI64, (*Buffer).ReadI64),
+		typed("I64LE", le, int64(-1), (*Buffer).WriteI64LE, (*Buffer).ReadI64LE),
+		typed("I64BE", be, int64(math.MaxInt64), (*Buffer).WriteI64BE, (*Buffer).ReadI64BE),
+		typed("F32", nil, float32(-1.5), (*Buffer).WriteF32, (*Buffer).ReadF32),
+		typed("F32LE", le, float32(math.Pi), (*Buffer).WriteF32LE, (*Buffer).ReadF32LE),
+		typed("F32BE", be, float32(math.SmallestNonzeroFloat32), (*Buffer).WriteF32BE, (*Buffer).ReadF32BE),
+		typed("F64", nil, -math.E, (*Buffer).WriteF64, (*Buffer).ReadF64),
+		typed("F64LE", le, math.MaxFloat64, (*Buffer).WriteF64LE, (*Buffer).ReadF64LE),
+		typed("F64BE", be, math.Inf(-1), (*Buffer).WriteF64BE, (*Buffer).ReadF64BE),
+	}
+	for _, order := range []binary.ByteOrder{le, be} {
+		for _, test := range tests {
+			b := NewBuffer("typed").WithByteOrder(order)
+			if err := test.write(b); err != nil {
+				t.Fatalf("%s Write%s = %v", order, test.name, err)
+			}
+			encoding := test.order
+			if encoding == nil {
+				encoding = order
+			}
+			var want bytes.Buffer
+			binary.Write(&want, encoding, test.value)
+			if got := b.Bytes(); !bytes.Equal(got, want.Bytes()) {
+				t.Fatalf("%s Write%s encoded % x, want % x", order, test.name, got, want.Bytes())
+			}
+
+			b.Seek(0, io.SeekStart)
+			if got, err := test.read(b); got != test.value || err != nil {
+				t.Fatalf("%s Read%s = %v, %v, want %v", order, test.name, got, err, test.value)
+			}
+			if _, err := test.read(b); err != io.EOF {
+				t.Fatalf("%s Read%s at the end = %v, want io.EOF", order, test.name, err)
+			}
+
+			if size := want.Len(); size > 1 {
+				b.Truncate(int64(size - 1))
+				b.Seek(0, io.SeekStart)
+				if _, err := test.read(b); err != io.ErrUnexpectedEOF || b.Tell() != 0 {
+					t.Fatalf("%s Read%s of a truncated value = %v at offset %d, want io.ErrUnexpectedEOF at 0", order, test.name, err, b.Tell())
+				}
+			}
+		}
+	}
+}
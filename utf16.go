@@ -0,0 +1,209 @@
+package crunchio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+// WriteUTF16 writes s as UTF-16 code units in the given byte order
+func (b *Buffer) WriteUTF16(s string, order binary.ByteOrder) (int, error) {
+	if b == nil {
+		panic("WRITEUTF16: buffer is nil")
+	}
+	return b.Write(encodeUTF16(s, order, false))
+}
+
+// WriteUTF16CString writes s as UTF-16 code units followed by a NUL code unit
+func (b *Buffer) WriteUTF16CString(s string, order binary.ByteOrder) (int, error) {
+	if b == nil {
+		panic("WRITEUTF16CSTRING: buffer is nil")
+	}
+	for _, r := range s {
+		if r == 0 {
+			return 0, fmt.Errorf("buffer: writeutf16cstring: string contains a NUL character")
+		}
+	}
+	return b.Write(encodeUTF16(s, order, true))
+}
+
+// ReadUTF16 reads n UTF-16 code units in the given byte order and decodes them
+func (b *Buffer) ReadUTF16(n int, order binary.ByteOrder) (string, error) {
+	if b == nil {
+		panic("READUTF16: buffer is nil")
+	}
+	if n < 0 {
+		return "", fmt.Errorf("buffer: readutf16: negative length %d", n)
+	}
+	data := make([]byte, 2*n)
+	if err := b.readExact(data); err != nil {
+		if err == io.EOF && n > 0 {
+			err = io.ErrUnexpectedEOF
+		}
+		return "", err
+	}
+	units := make([]uint16, n)
+	for i := range units {
+		units[i] = order.Uint16(data[2*i:])
+	}
+	return string(utf16.Decode(units)), nil
+}
+
+// ReadUTF16CString reads UTF-16 code units up to and including a NUL code
+// unit, returning the decoded string without the terminator and restoring the
+// cursor on failure
+func (b *Buffer) ReadUTF16CString(order binary.ByteOrder) (string, error) {
+	if b == nil {
+		panic("READUTF16CSTRING: buffer is nil")
+	}
+	start := b.TellBit()
+	var units []uint16
+	var unit [2]byte
+	for {
+		if err := b.readExact(unit[:]); err != nil {
+			b.SeekBit(start)
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return "", err
+		}
+		c := order.Uint16(unit[:])
+		if c == 0 {
+			break
+		}
+		units = append(units, c)
+	}
+	return string(utf16.Decode(units)), nil
+}
+
+// UTF16Options controls how WriteUTF16String and ReadUTF16String encode a
+// string
+type UTF16Options struct {
+	// Order is the byte order of the code units, nil for the buffer's own
+	Order binary.ByteOrder
+	// BOM writes a byte-order mark first, and on reads detects one and
+	// switches to the byte order it names
+	BOM bool
+	// Terminate ends the string with a NUL code unit
+	Terminate bool
+}
+
+// WriteUTF16String writes s as UTF-16 code units according to opts
+func (b *Buffer) WriteUTF16String(s string, opts UTF16Options) (int, error) {
+	if b == nil {
+		panic("WRITEUTF16STRING: buffer is nil")
+	}
+	order := opts.Order
+	if order == nil {
+		order = b.byteOrder()
+	}
+	if opts.Terminate && strings.ContainsRune(s, 0) {
+		return 0, fmt.Errorf("buffer: writeutf16string: string contains a NUL character")
+	}
+	data := encodeUTF16(s, order, opts.Terminate)
+	if opts.BOM {
+		var bom [2]byte
+		order.PutUint16(bom[:], 0xFEFF)
+		data = append(bom[:], data...)
+	}
+	return b.Write(data)
+}
+
+// ReadUTF16String reads a string written by WriteUTF16String with matching
+// opts, n code units long unless opts.Terminate is set, in which case it reads
+// up to the NUL code unit and n is ignored, restoring the cursor on failure
+func (b *Buffer) ReadUTF16String(n int, opts UTF16Options) (s string, err error) {
+	if b == nil {
+		panic("READUTF16STRING: buffer is nil")
+	}
+	order := opts.Order
+	if order == nil {
+		order = b.byteOrder()
+	}
+	start := b.TellBit()
+	if opts.BOM {
+		var bom [2]byte
+		if err = b.readExact(bom[:]); err == nil {
+			switch {
+			case bom == [2]byte{0xFF, 0xFE}:
+				order = binary.LittleEndian
+			case bom == [2]byte{0xFE, 0xFF}:
+				order = binary.BigEndian
+			default:
+				b.SeekBit(start)
+			}
+		} else if err != io.EOF || opts.Terminate || n > 0 {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return "", err
+		}
+	}
+	if opts.Terminate {
+		s, err = b.ReadUTF16CString(order)
+	} else {
+		s, err = b.ReadUTF16(n, order)
+	}
+	if err != nil {
+		b.SeekBit(start)
+	}
+	return
+}
+
+func encodeUTF16(s string, order binary.ByteOrder, terminate bool) []byte {
+	units := utf16.Encode([]rune(s))
+	if terminate {
+		units = append(units, 0)
+	}
+	data := make([]byte, 2*len(units))
+	for i, unit := range units {
+		order.PutUint16(data[2*i:], unit)
+	}
+	return data
+}
+
+// byteOrderMarks lists the recognized byte-order marks and their encodings
+var byteOrderMarks = []struct {
+	encoding string
+	mark     []byte
+}{
+	{"utf-8", []byte{0xEF, 0xBB, 0xBF}},
+	{"utf-16le", []byte{0xFF, 0xFE}},
+	{"utf-16be", []byte{0xFE, 0xFF}},
+}
+
+// DetectBOM inspects the start of the buffer for a byte-order mark, returning
+// its encoding ("utf-8", "utf-16le" or "utf-16be") and length, or "" and 0
+func (b *Buffer) DetectBOM() (encoding string, bomLen int) {
+	if b == nil {
+		panic("DETECTBOM: buffer is nil")
+	}
+	var head [3]byte
+	read, _ := b.readOffset(head[:], 0)
+	for _, bom := range byteOrderMarks {
+		if read >= len(bom.mark) && string(head[:len(bom.mark)]) == string(bom.mark) {
+			return bom.encoding, len(bom.mark)
+		}
+	}
+	return "", 0
+}
+
+// SkipBOM moves the offset past a byte-order mark at the start of the buffer
+// if it has not been read yet, returning its encoding or ""
+func (b *Buffer) SkipBOM() string {
+	if b == nil {
+		panic("SKIPBOM: buffer is nil")
+	}
+	encoding, bomLen := b.DetectBOM()
+	if bomLen > 0 {
+		b.Lock()
+		if b.offset < int64(bomLen) {
+			b.offset = int64(bomLen)
+			b.bit = 0
+		}
+		b.Unlock()
+	}
+	return encoding
+}
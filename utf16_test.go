@@ -0,0 +1,139 @@
+package crunchio
+
+import (
+	"encoding/binary"
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestUTF16RoundTrip(t *testing.T) {
+	const s = "a😀é𝄞"
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		b := NewBuffer("utf16")
+		n, err := b.WriteUTF16(s, order)
+		if err != nil || n != 12 {
+			t.Fatalf("WriteUTF16 = %d, %v, want 12", n, err)
+		}
+		b.Seek(0, io.SeekStart)
+		got, err := b.ReadUTF16(6, order)
+		if err != nil || got != s {
+			t.Fatalf("ReadUTF16 in %v = %q, %v", order, got, err)
+		}
+	}
+}
+
+func TestUTF16Surrogates(t *testing.T) {
+	b := NewBuffer("utf16")
+	b.WriteUTF16("😀", binary.BigEndian)
+	if got := b.Bytes(); !slices.Equal(got, []byte{0xD8, 0x3D, 0xDE, 0x00}) {
+		t.Fatalf("WriteUTF16 encoded U+1F600 as % x", got)
+	}
+}
+
+func TestUTF16CStringRoundTrip(t *testing.T) {
+	b := NewBuffer("utf16")
+	b.WriteUTF16CString("x😀", binary.LittleEndian)
+	b.WriteUTF16("tail", binary.LittleEndian)
+	b.Seek(0, io.SeekStart)
+	got, err := b.ReadUTF16CString(binary.LittleEndian)
+	if err != nil || got != "x😀" {
+		t.Fatalf("ReadUTF16CString = %q, %v", got, err)
+	}
+	if b.Tell() != 8 {
+		t.Fatalf("ReadUTF16CString stopped at %d, want 8", b.Tell())
+	}
+	if _, err := NewBuffer("utf16", []byte{'a', 0}).ReadUTF16CString(binary.LittleEndian); err == nil {
+		t.Fatal("ReadUTF16CString without a terminator succeeded")
+	}
+}
+
+func TestUTF16CStringRestoresBit(t *testing.T) {
+	b := NewBuffer("utf16", []byte{0xFF, 'a', 0, 'b'})
+	b.ReadBits(3)
+	if _, err := b.ReadUTF16CString(binary.LittleEndian); err != io.ErrUnexpectedEOF || b.TellBit() != 3 {
+		t.Fatalf("ReadUTF16CString without a terminator = %v at bit %d, want io.ErrUnexpectedEOF at 3", err, b.TellBit())
+	}
+}
+
+func TestUTF16String(t *testing.T) {
+	const s = "h😀"
+	for _, tt := range []struct {
+		opts UTF16Options
+		data []byte
+	}{
+		{UTF16Options{}, []byte{'h', 0, 0x3D, 0xD8, 0x00, 0xDE}},
+		{UTF16Options{Order: binary.BigEndian}, []byte{0, 'h', 0xD8, 0x3D, 0xDE, 0x00}},
+		{UTF16Options{BOM: true}, []byte{0xFF, 0xFE, 'h', 0, 0x3D, 0xD8, 0x00, 0xDE}},
+		{UTF16Options{Order: binary.BigEndian, BOM: true, Terminate: true}, []byte{0xFE, 0xFF, 0, 'h', 0xD8, 0x3D, 0xDE, 0x00, 0, 0}},
+	} {
+		b := NewBuffer("utf16")
+		if n, err := b.WriteUTF16String(s, tt.opts); n != len(tt.data) || err != nil {
+			t.Fatalf("WriteUTF16String(%+v) = %d, %v", tt.opts, n, err)
+		}
+		if got := b.Bytes(); !slices.Equal(got, tt.data) {
+			t.Fatalf("WriteUTF16String(%+v) encoded % x, want % x", tt.opts, got, tt.data)
+		}
+		b.WriteU8(0xAA)
+		b.Seek(0, io.SeekStart)
+		if got, err := b.ReadUTF16String(3, tt.opts); got != s || err != nil {
+			t.Fatalf("ReadUTF16String(%+v) = %q, %v", tt.opts, got, err)
+		}
+		if b.Tell() != int64(len(tt.data)) {
+			t.Fatalf("ReadUTF16String(%+v) stopped at %d, want %d", tt.opts, b.Tell(), len(tt.data))
+		}
+	}
+
+	// a BOM naming the other order overrides opts.Order
+	b := NewBuffer("utf16")
+	b.WriteUTF16String(s, UTF16Options{Order: binary.BigEndian, BOM: true})
+	b.Seek(0, io.SeekStart)
+	if got, err := b.ReadUTF16String(3, UTF16Options{Order: binary.LittleEndian, BOM: true}); got != s || err != nil {
+		t.Fatalf("ReadUTF16String with a big-endian BOM = %q, %v", got, err)
+	}
+
+	if _, err := NewBuffer("utf16").WriteUTF16String("a\x00", UTF16Options{Terminate: true}); err == nil {
+		t.Fatal("WriteUTF16String of a terminated string with a NUL succeeded")
+	}
+}
+
+func TestUTF16StringRestoresCursor(t *testing.T) {
+	b := NewBuffer("utf16", []byte{0xFF, 0xFF, 0xFE, 'a', 0})
+	b.ReadBits(5)
+	for _, opts := range []UTF16Options{{BOM: true, Terminate: true}, {}} {
+		if _, err := b.ReadUTF16String(4, opts); err != io.ErrUnexpectedEOF || b.TellBit() != 5 {
+			t.Fatalf("ReadUTF16String(%+v) cut short = %v at bit %d, want io.ErrUnexpectedEOF at 5", opts, err, b.TellBit())
+		}
+	}
+}
+
+func TestDetectSkipBOM(t *testing.T) {
+	for _, tt := range []struct {
+		data     string
+		encoding string
+		bomLen   int
+	}{
+		{"\xEF\xBB\xBFtext", "utf-8", 3},
+		{"\xFF\xFEt\x00", "utf-16le", 2},
+		{"\xFE\xFF\x00t", "utf-16be", 2},
+		{"text", "", 0},
+		{"\xEF\xBB", "", 0},
+		{"", "", 0},
+	} {
+		b := NewBuffer("bom", []byte(tt.data))
+		if encoding, bomLen := b.DetectBOM(); encoding != tt.encoding || bomLen != tt.bomLen {
+			t.Fatalf("DetectBOM(%q) = %q, %d, want %q, %d", tt.data, encoding, bomLen, tt.encoding, tt.bomLen)
+		}
+		if b.Tell() != 0 {
+			t.Fatalf("DetectBOM(%q) moved the offset to %d", tt.data, b.Tell())
+		}
+		if encoding := b.SkipBOM(); encoding != tt.encoding || b.Tell() != int64(tt.bomLen) {
+			t.Fatalf("SkipBOM(%q) = %q at offset %d", tt.data, encoding, b.Tell())
+		}
+	}
+	b := NewBuffer("bom", []byte("\xEF\xBB\xBFtext"))
+	b.Seek(5, io.SeekStart)
+	if b.SkipBOM(); b.Tell() != 5 {
+		t.Fatalf("SkipBOM after the mark was read moved the offset to %d", b.Tell())
+	}
+}
@@ -0,0 +1,114 @@
+package crunchio
+
+import (
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// ValidUTF8 reports whether the contents of the buffer are valid UTF-8
+func (b *Buffer) ValidUTF8() bool {
+	if b == nil {
+		panic("VALIDUTF8: buffer is nil")
+	}
+	b.Lock()
+	defer b.Unlock()
+	return utf8.Valid(b.snapshot())
+}
+
+// FirstInvalidUTF8 returns the offset of the first byte that does not start a
+// valid UTF-8 sequence, including a truncated one at the end, or -1 if the
+// contents are valid
+func (b *Buffer) FirstInvalidUTF8() int64 {
+	if b == nil {
+		panic("FIRSTINVALIDUTF8: buffer is nil")
+	}
+	b.Lock()
+	data := b.snapshot()
+	b.Unlock()
+	for at := 0; at < len(data); {
+		r, size := utf8.DecodeRune(data[at:])
+		if r == utf8.RuneError && size <= 1 {
+			return int64(at)
+		}
+		at += size
+	}
+	return -1
+}
+
+// ReadRune decodes the UTF-8 rune at the current offset and advances past it,
+// implementing io.RuneReader, an invalid or truncated encoding reads as
+// utf8.RuneError with size 1 like bytes.Reader
+func (b *Buffer) ReadRune() (r rune, size int, err error) {
+	if b == nil {
+		panic("READRUNE: buffer is nil")
+	}
+	at, data, err := b.readRune()
+	if err != nil {
+		return 0, 0, err
+	}
+	b.afterRead(at, data)
+	r, size = utf8.DecodeRune(data)
+	return r, size, nil
+}
+
+// readRune consumes the bytes of the rune at the current offset, remembering
+// where it ended for UnreadRune
+func (b *Buffer) readRune() (at int64, data []byte, err error) {
+	b.Lock()
+	defer b.Unlock()
+	if b.Closed() {
+		return 0, nil, fmt.Errorf("buffer: readrune: %w", ErrClosed)
+	}
+	b.alignBit()
+	at = b.offset
+	var scratch [utf8.UTFMax]byte
+	var read int
+	if b.parent != nil {
+		window, from := b.windowRead(scratch[:], at)
+		read, err = b.parent.readOffset(window, from)
+	} else {
+		read, err = b.readOffsetLocked(scratch[:], at)
+	}
+	if err != nil && err != io.EOF {
+		return at, nil, err
+	}
+	if read == 0 {
+		return at, nil, io.EOF
+	}
+	_, size := utf8.DecodeRune(scratch[:read])
+	b.offset += int64(size)
+	b.runeEnd = b.offset
+	b.runeSize = int64(size)
+	return at, append([]byte{}, scratch[:size]...), nil
+}
+
+// WriteRune writes the UTF-8 encoding of r at the current offset, implementing
+// the method of the same name on bytes.Buffer, invalid runes are written as
+// utf8.RuneError
+func (b *Buffer) WriteRune(r rune) (int, error) {
+	if b == nil {
+		panic("WRITERUNE: buffer is nil")
+	}
+	var scratch [utf8.UTFMax]byte
+	return b.Write(utf8.AppendRune(scratch[:0], r))
+}
+
+// UnreadRune moves the offset back over the rune just read by ReadRune,
+// implementing io.RuneScanner, it fails if the offset has moved since
+func (b *Buffer) UnreadRune() error {
+	if b == nil {
+		panic("UNREADRUNE: buffer is nil")
+	}
+	b.Lock()
+	defer b.Unlock()
+	if b.Closed() {
+		return fmt.Errorf("buffer: unreadrune: %w", ErrClosed)
+	}
+	if b.runeSize == 0 || b.offset != b.runeEnd || b.bit != 0 {
+		return fmt.Errorf("buffer: unreadrune: previous operation was not a successful ReadRune")
+	}
+	b.offset -= b.runeSize
+	b.runeSize = 0
+	return nil
+}
@@ -0,0 +1,103 @@
+package crunchio
+
+import (
+	"io"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestValidUTF8(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		data string
+		bad  int64
+	}{
+		{"empty", "", -1},
+		{"valid", "héllo, 世界 🙂", -1},
+		{"invalid byte", "héllo\xffworld", 6},
+		{"stray continuation", "ab\x80cd", 2},
+		{"truncated at the end", "héllo 世\xe7\x95", 10},
+	} {
+		b := NewBuffer("utf8", []byte(test.data))
+		if valid := b.ValidUTF8(); valid != (test.bad < 0) {
+			t.Fatalf("%s: ValidUTF8 = %v", test.name, valid)
+		}
+		if bad := b.FirstInvalidUTF8(); bad != test.bad {
+			t.Fatalf("%s: FirstInvalidUTF8 = %d, want %d", test.name, bad, test.bad)
+		}
+	}
+}
+
+func TestValidUTF8Reference(t *testing.T) {
+	b := NewBuffer("utf8", []byte("valid"))
+	ref := b.Reference()
+	ref.Read(make([]byte, 3))
+	if !ref.ValidUTF8() || ref.FirstInvalidUTF8() != -1 {
+		t.Fatal("reference of valid text reports it invalid")
+	}
+	b.WriteAt([]byte{0xC3}, 5)
+	if ref.ValidUTF8() || ref.FirstInvalidUTF8() != 5 {
+		t.Fatalf("reference after the parent grew a bad byte reports %d", ref.FirstInvalidUTF8())
+	}
+}
+
+func TestRuneRoundTrip(t *testing.T) {
+	runes := []rune{'a', 'é', '世', '🙂', utf8.MaxRune}
+	b := NewBuffer("runes")
+	for _, r := range runes {
+		if n, err := b.WriteRune(r); n != utf8.RuneLen(r) || err != nil {
+			t.Fatalf("WriteRune(%q) = %d, %v", r, n, err)
+		}
+	}
+	if n, err := b.WriteRune(-1); n != 3 || err != nil {
+		t.Fatalf("WriteRune(-1) = %d, %v, want the 3-byte replacement character", n, err)
+	}
+	b.Seek(0, io.SeekStart)
+	for _, want := range append(runes, utf8.RuneError) {
+		if r, size, err := b.ReadRune(); r != want || size != utf8.RuneLen(want) || err != nil {
+			t.Fatalf("ReadRune = %q, %d, %v, want %q", r, size, err, want)
+		}
+	}
+	if _, _, err := b.ReadRune(); err != io.EOF {
+		t.Fatalf("ReadRune at the end = %v, want io.EOF", err)
+	}
+}
+
+func TestReadRuneInvalid(t *testing.T) {
+	b := NewBuffer("runes", []byte("\xffa\xe4\xb8"))
+	for _, want := range []struct {
+		r    rune
+		size int
+	}{{utf8.RuneError, 1}, {'a', 1}, {utf8.RuneError, 1}, {utf8.RuneError, 1}} {
+		if r, size, err := b.ReadRune(); r != want.r || size != want.size || err != nil {
+			t.Fatalf("ReadRune = %q, %d, %v, want %q, %d", r, size, err, want.r, want.size)
+		}
+	}
+}
+
+func TestUnreadRune(t *testing.T) {
+	b := NewBuffer("runes", []byte("世a"))
+	if err := b.UnreadRune(); err == nil {
+		t.Fatal("UnreadRune without a ReadRune succeeded")
+	}
+	b.ReadRune()
+	if err := b.UnreadRune(); err != nil || b.Tell() != 0 {
+		t.Fatalf("UnreadRune = %v at offset %d, want 0", err, b.Tell())
+	}
+	if err := b.UnreadRune(); err == nil {
+		t.Fatal("second UnreadRune succeeded")
+	}
+	if r, _, _ := b.ReadRune(); r != '世' {
+		t.Fatalf("ReadRune after UnreadRune = %q", r)
+	}
+	b.ReadByte()
+	if err := b.UnreadRune(); err == nil {
+		t.Fatal("UnreadRune after ReadByte succeeded")
+	}
+	b.Seek(0, io.SeekStart)
+	b.ReadRune()
+	b.ReadBit()
+	if err := b.UnreadRune(); err == nil {
+		t.Fatal("UnreadRune after ReadBit succeeded")
+	}
+}
@@ -0,0 +1,129 @@
+package crunchio
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Numeric is the set of fixed-size numeric types supported by WriteValue and
+// ReadValue
+type Numeric interface {
+	int8 | uint8 | int16 | uint16 | int32 | uint32 | int64 | uint64 | float32 | float64
+}
+
+// WriteValue writes v at the current offset in the buffer's byte order
+func WriteValue[T Numeric](b *Buffer, v T) (int, error) {
+	if b == nil {
+		panic("WRITEVALUE: buffer is nil")
+	}
+	return writeValue(b, b.byteOrder(), v)
+}
+
+// writeValue writes v at the current offset in the given byte order
+func writeValue[T Numeric](b *Buffer, order binary.ByteOrder, v T) (int, error) {
+	var scratch [8]byte
+	size := binary.Size(v)
+	putValue(scratch[:], order, v)
+	return b.Write(scratch[:size])
+}
+
+// putValue encodes v at the start of data in the given byte order
+func putValue[T Numeric](data []byte, order binary.ByteOrder, v T) {
+	switch v := any(v).(type) {
+	case int8:
+		data[0] = byte(v)
+	case uint8:
+		data[0] = v
+	case int16:
+		order.PutUint16(data, uint16(v))
+	case uint16:
+		order.PutUint16(data, v)
+	case int32:
+		order.PutUint32(data, uint32(v))
+	case uint32:
+		order.PutUint32(data, v)
+	case int64:
+		order.PutUint64(data, uint64(v))
+	case uint64:
+		order.PutUint64(data, v)
+	case float32:
+		order.PutUint32(data, math.Float32bits(v))
+	case float64:
+		order.PutUint64(data, math.Float64bits(v))
+	}
+}
+
+// encodeValues encodes values back to back in the given byte order
+func encodeValues[T Numeric](values []T, order binary.ByteOrder) []byte {
+	var zero T
+	size := binary.Size(zero)
+	data := make([]byte, size*len(values))
+	for i := range values {
+		putValue(data[size*i:], order, values[i])
+	}
+	return data
+}
+
+// ReadValue reads a T at the current offset in the buffer's byte order,
+// returning io.ErrUnexpectedEOF without advancing if too few bytes remain
+func ReadValue[T Numeric](b *Buffer) (v T, err error) {
+	if b == nil {
+		panic("READVALUE: buffer is nil")
+	}
+	return readValue[T](b, b.byteOrder())
+}
+
+// readValue reads a T at the current offset in the given byte order
+func readValue[T Numeric](b *Buffer, order binary.ByteOrder) (v T, err error) {
+	var scratch [8]byte
+	size := binary.Size(v)
+	if err = b.readExact(scratch[:size]); err != nil {
+		return
+	}
+	v = decodeValue[T](scratch[:size], order)
+	return
+}
+
+// readValues fills dst with consecutive Ts read at the current offset in the
+// buffer's byte order, returning io.ErrUnexpectedEOF without advancing if too
+// few bytes remain
+func readValues[T Numeric](b *Buffer, dst []T) error {
+	var zero T
+	size := binary.Size(zero)
+	data := make([]byte, size*len(dst))
+	if err := b.readExact(data); err != nil {
+		return err
+	}
+	order := b.byteOrder()
+	for i := range dst {
+		dst[i] = decodeValue[T](data[size*i:], order)
+	}
+	return nil
+}
+
+// decodeValue decodes a T from the start of data in the given byte order
+func decodeValue[T Numeric](data []byte, order binary.ByteOrder) (v T) {
+	switch p := any(&v).(type) {
+	case *int8:
+		*p = int8(data[0])
+	case *uint8:
+		*p = data[0]
+	case *int16:
+		*p = int16(order.Uint16(data))
+	case *uint16:
+		*p = order.Uint16(data)
+	case *int32:
+		*p = int32(order.Uint32(data))
+	case *uint32:
+		*p = order.Uint32(data)
+	case *int64:
+		*p = int64(order.Uint64(data))
+	case *uint64:
+		*p = order.Uint64(data)
+	case *float32:
+		*p = math.Float32frombits(order.Uint32(data))
+	case *float64:
+		*p = math.Float64frombits(order.Uint64(data))
+	}
+	return
+}
@@ -0,0 +1,65 @@
+package crunchio
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+)
+
+// roundTripValue writes v with WriteValue in both byte orders and reads it
+// back with ReadValue
+func roundTripValue[T Numeric](t *testing.T, v T, size int) {
+	t.Helper()
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		b := NewBuffer("value")
+		b.SetByteOrder(order)
+		if n, err := WriteValue(b, v); n != size || err != nil {
+			t.Fatalf("WriteValue(%T %v) = %d, %v, want %d", v, v, n, err, size)
+		}
+		b.Seek(0, io.SeekStart)
+		got, err := ReadValue[T](b)
+		if err != nil || got != v {
+			t.Fatalf("ReadValue[%T] in %v = %v, %v, want %v", v, order, got, err, v)
+		}
+		if b.Tell() != int64(size) {
+			t.Fatalf("ReadValue[%T] advanced to %d, want %d", v, b.Tell(), size)
+		}
+	}
+}
+
+func TestValueRoundTrip(t *testing.T) {
+	roundTripValue(t, int8(math.MinInt8), 1)
+	roundTripValue(t, uint8(math.MaxUint8), 1)
+	roundTripValue(t, int16(math.MinInt16), 2)
+	roundTripValue(t, uint16(0xBEEF), 2)
+	roundTripValue(t, int32(-123456789), 4)
+	roundTripValue(t, uint32(0xDEADBEEF), 4)
+	roundTripValue(t, int64(math.MinInt64), 8)
+	roundTripValue(t, uint64(math.MaxUint64), 8)
+	roundTripValue(t, float32(-1.5), 4)
+	roundTripValue(t, math.Pi, 8)
+}
+
+func TestValueByteOrder(t *testing.T) {
+	b := NewBuffer("value")
+	b.SetByteOrder(binary.BigEndian)
+	WriteValue(b, uint32(0x01020304))
+	if got := b.Bytes(); got[0] != 1 || got[3] != 4 {
+		t.Fatalf("big-endian WriteValue wrote % x", got)
+	}
+}
+
+func TestReadValueShort(t *testing.T) {
+	b := NewBuffer("value", []byte{1, 2, 3})
+	if _, err := ReadValue[uint32](b); err != io.ErrUnexpectedEOF {
+		t.Fatalf("ReadValue of a short buffer = %v, want io.ErrUnexpectedEOF", err)
+	}
+	if b.Tell() != 0 {
+		t.Fatalf("failed ReadValue advanced to %d", b.Tell())
+	}
+	b.Seek(3, io.SeekStart)
+	if _, err := ReadValue[uint8](b); err != io.EOF {
+		t.Fatalf("ReadValue at the end = %v, want io.EOF", err)
+	}
+}
@@ -0,0 +1,94 @@
+package crunchio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// WriteUvarint writes v at the current offset in the encoding/binary varint
+// format, returning the number of bytes written
+func (b *Buffer) WriteUvarint(v uint64) (int, error) {
+	if b == nil {
+		panic("WRITEUVARINT: buffer is nil")
+	}
+	var scratch [binary.MaxVarintLen64]byte
+	return b.Write(scratch[:binary.PutUvarint(scratch[:], v)])
+}
+
+// WriteVarint writes v at the current offset in the encoding/binary zig-zag
+// varint format, returning the number of bytes written
+func (b *Buffer) WriteVarint(v int64) (int, error) {
+	if b == nil {
+		panic("WRITEVARINT: buffer is nil")
+	}
+	var scratch [binary.MaxVarintLen64]byte
+	return b.Write(scratch[:binary.PutVarint(scratch[:], v)])
+}
+
+// ReadUvarint reads a varint written by WriteUvarint at the current offset and
+// advances past it, returning io.ErrUnexpectedEOF without advancing if it is
+// cut short
+func (b *Buffer) ReadUvarint() (uint64, error) {
+	if b == nil {
+		panic("READUVARINT: buffer is nil")
+	}
+	at, data, err := b.readVarint()
+	if err != nil {
+		return 0, err
+	}
+	b.afterRead(at, data)
+	v, _ := binary.Uvarint(data)
+	return v, nil
+}
+
+// ReadVarint reads a varint written by WriteVarint at the current offset and
+// advances past it, returning io.ErrUnexpectedEOF without advancing if it is
+// cut short
+func (b *Buffer) ReadVarint() (int64, error) {
+	if b == nil {
+		panic("READVARINT: buffer is nil")
+	}
+	at, data, err := b.readVarint()
+	if err != nil {
+		return 0, err
+	}
+	b.afterRead(at, data)
+	v, _ := binary.Varint(data)
+	return v, nil
+}
+
+// readVarint consumes the bytes of the varint at the current offset, which
+// decode the same whether signed or not
+func (b *Buffer) readVarint() (at int64, data []byte, err error) {
+	b.Lock()
+	defer b.Unlock()
+	if b.Closed() {
+		return 0, nil, fmt.Errorf("buffer: readvarint: %w", ErrClosed)
+	}
+	b.alignBit()
+	at = b.offset
+	var scratch [binary.MaxVarintLen64]byte
+	var read int
+	if b.parent != nil {
+		window, from := b.windowRead(scratch[:], at)
+		read, err = b.parent.readOffset(window, from)
+	} else {
+		read, err = b.readOffsetLocked(scratch[:], at)
+	}
+	if err != nil && err != io.EOF {
+		return at, nil, err
+	}
+	if read == 0 {
+		return at, nil, io.EOF
+	}
+	_, n := binary.Uvarint(scratch[:read])
+	if n == 0 {
+		return at, nil, io.ErrUnexpectedEOF
+	}
+	if n < 0 {
+		return at, nil, fmt.Errorf("buffer: readvarint: varint overflows 64 bits")
+	}
+	b.offset += int64(n)
+	return at, append([]byte{}, scratch[:n]...), nil
+}
@@ -0,0 +1,64 @@
+package crunchio
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+)
+
+func TestVarintRoundTrip(t *testing.T) {
+	signed := []int64{0, 1, -1, 63, -64, 64, -65, math.MaxInt32, math.MinInt32, math.MaxInt64, math.MinInt64}
+	unsigned := []uint64{0, 1, 127, 128, 1 << 35, math.MaxUint32, math.MaxUint64}
+	b := NewBuffer("varint")
+	for _, v := range signed {
+		var scratch [binary.MaxVarintLen64]byte
+		if n, err := b.WriteVarint(v); n != binary.PutVarint(scratch[:], v) || err != nil {
+			t.Fatalf("WriteVarint(%d) = %d, %v", v, n, err)
+		}
+	}
+	for _, v := range unsigned {
+		var scratch [binary.MaxVarintLen64]byte
+		if n, err := b.WriteUvarint(v); n != binary.PutUvarint(scratch[:], v) || err != nil {
+			t.Fatalf("WriteUvarint(%d) = %d, %v", v, n, err)
+		}
+	}
+
+	b.Seek(0, io.SeekStart)
+	for _, want := range signed {
+		if got, err := b.ReadVarint(); got != want || err != nil {
+			t.Fatalf("ReadVarint = %d, %v, want %d", got, err, want)
+		}
+	}
+	for _, want := range unsigned {
+		if got, err := b.ReadUvarint(); got != want || err != nil {
+			t.Fatalf("ReadUvarint = %d, %v, want %d", got, err, want)
+		}
+	}
+	if _, err := b.ReadVarint(); err != io.EOF {
+		t.Fatalf("ReadVarint at the end = %v, want io.EOF", err)
+	}
+}
+
+func TestVarintTruncated(t *testing.T) {
+	b := NewBuffer("varint")
+	b.WriteUvarint(math.MaxUint64)
+	b.Truncate(b.Size() - 1)
+	b.Seek(0, io.SeekStart)
+	if _, err := b.ReadUvarint(); err != io.ErrUnexpectedEOF || b.Tell() != 0 {
+		t.Fatalf("ReadUvarint cut short = %v at offset %d, want io.ErrUnexpectedEOF at 0", err, b.Tell())
+	}
+	if _, err := b.ReadVarint(); err != io.ErrUnexpectedEOF || b.Tell() != 0 {
+		t.Fatalf("ReadVarint cut short = %v at offset %d, want io.ErrUnexpectedEOF at 0", err, b.Tell())
+	}
+
+	s := NewBuffer("varint", []byte{0x80, 0x80, 0x01}).Slice(0, 2)
+	if _, err := s.ReadUvarint(); err != io.ErrUnexpectedEOF {
+		t.Fatalf("ReadUvarint cut short by a slice = %v, want io.ErrUnexpectedEOF", err)
+	}
+
+	overflow := NewBuffer("varint", []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x02})
+	if _, err := overflow.ReadUvarint(); err == nil || err == io.ErrUnexpectedEOF {
+		t.Fatalf("ReadUvarint of an overlong varint = %v, want an overflow error", err)
+	}
+}
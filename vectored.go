@@ -0,0 +1,126 @@
+package crunchio
+
+import (
+	"fmt"
+)
+
+// WriteVectored writes each of srcs in order at the current offset as one
+// locked operation, growing the buffer at most once
+func (b *Buffer) WriteVectored(srcs ...[]byte) (wrote int, err error) {
+	if b == nil {
+		panic("WRITEVECTORED: buffer is nil")
+	}
+	at, wrote, err := b.writeVectored(srcs)
+	for i, left := 0, wrote; i < len(srcs) && left > 0; i++ {
+		n := min(len(srcs[i]), left)
+		b.afterWrite(at, srcs[i][:n])
+		at += int64(n)
+		left -= n
+	}
+	return
+}
+
+func (b *Buffer) writeVectored(srcs [][]byte) (at int64, wrote int, err error) {
+	b.Lock()
+	defer b.Unlock()
+	defer func() {
+		for i, offset, left := 0, at, wrote; i < len(srcs) && left > 0; i++ {
+			n := min(len(srcs[i]), left)
+			b.hash(offset, srcs[i][:n])
+			offset += int64(n)
+			left -= n
+		}
+	}()
+	if b.Closed() {
+		return 0, 0, fmt.Errorf("buffer: writevectored: %w", ErrClosed)
+	}
+	if b.readOnly {
+		return 0, 0, fmt.Errorf("buffer: writevectored: %w", ErrReadOnly)
+	}
+	b.alignBit()
+	at = b.offset
+	total := 0
+	for i := 0; i < len(srcs); i++ {
+		total += len(srcs[i])
+	}
+	b.unshare()
+	if b.parent != nil || b.file != nil {
+		data := make([]byte, 0, total)
+		for i := 0; i < len(srcs); i++ {
+			data = append(data, srcs[i]...)
+		}
+		if b.parent != nil {
+			var to int64
+			if to, err = b.windowWrite("writevectored", len(data), b.offset); err != nil {
+				return
+			}
+			wrote, err = b.parent.WriteOffset(data, to)
+		} else {
+			wrote, err = b.writeOffsetLocked(data, b.offset)
+		}
+		b.offset += int64(wrote)
+		return
+	}
+	buffer := b.Buffer()
+	if buffer == nil {
+		return at, 0, fmt.Errorf("buffer: writevectored: crunch buffer vanished")
+	}
+	if err = b.checkLimit("writevectored", b.offset+int64(total)); err != nil {
+		return at, 0, err
+	}
+	b.grow(buffer, b.offset, int64(total))
+	b.writes.Add(1)
+	for i := 0; i < len(srcs); i++ {
+		buffer.WriteBytes(b.offset, srcs[i])
+		b.offset += int64(len(srcs[i]))
+	}
+	wrote = total
+	return
+}
+
+// ReadVectored fills each of dsts in order from the current offset as one
+// locked operation, stopping early when the buffer runs out
+func (b *Buffer) ReadVectored(dsts ...[]byte) (read int, err error) {
+	if b == nil {
+		panic("READVECTORED: buffer is nil")
+	}
+	at, read, err := b.readVectored(dsts)
+	for i, left := 0, read; i < len(dsts) && left > 0; i++ {
+		n := min(len(dsts[i]), left)
+		b.afterRead(at, dsts[i][:n])
+		at += int64(n)
+		left -= n
+	}
+	return
+}
+
+func (b *Buffer) readVectored(dsts [][]byte) (at int64, read int, err error) {
+	b.Lock()
+	defer b.Unlock()
+	if b.Closed() {
+		return 0, 0, fmt.Errorf("buffer: readvectored: %w", ErrClosed)
+	}
+	b.alignBit()
+	at = b.offset
+	for i := 0; i < len(dsts); i++ {
+		if len(dsts[i]) == 0 {
+			continue
+		}
+		var n int
+		if b.parent != nil {
+			window, from := b.windowRead(dsts[i], b.offset)
+			n, err = b.parent.ReadOffset(window, from)
+		} else {
+			n, err = b.readOffsetLocked(dsts[i], b.offset)
+		}
+		b.offset += int64(n)
+		read += n
+		if err != nil || n < len(dsts[i]) {
+			break
+		}
+	}
+	if read > 0 {
+		err = nil
+	}
+	return
+}
@@ -0,0 +1,93 @@
+package crunchio
+
+import (
+	"bytes"
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestReadVectored(t *testing.T) {
+	b := NewBuffer("vectored", []byte("abcdefg"))
+	head, body, tail := make([]byte, 3), make([]byte, 6), make([]byte, 2)
+	n, err := b.ReadVectored(head, body, tail)
+	if n != 7 || err != nil {
+		t.Fatalf("ReadVectored = %d, %v, want 7, nil", n, err)
+	}
+	if string(head) != "abc" || string(body[:4]) != "defg" || !slices.Equal(body[4:], []byte{0, 0}) || !slices.Equal(tail, []byte{0, 0}) {
+		t.Fatalf("ReadVectored filled %q, %q, %q", head, body, tail)
+	}
+	if b.Tell() != 7 {
+		t.Fatalf("ReadVectored advanced to %d, want 7", b.Tell())
+	}
+	if n, err := b.ReadVectored(head, body); n != 0 || err != io.EOF {
+		t.Fatalf("ReadVectored at the end = %d, %v, want 0, io.EOF", n, err)
+	}
+}
+
+func TestReadVectoredHookOffsets(t *testing.T) {
+	b := NewBuffer("vectored", []byte("abcdefg"))
+	var got []int64
+	b.SetReadHook(func(offset int64, data []byte) {
+		got = append(got, offset, int64(len(data)))
+	})
+	b.ReadVectored(make([]byte, 3), make([]byte, 6), make([]byte, 2))
+	if want := []int64{0, 3, 3, 4}; !slices.Equal(got, want) {
+		t.Fatalf("hook saw %v, want %v", got, want)
+	}
+}
+
+func TestWriteVectored(t *testing.T) {
+	srcs := [][]byte{[]byte("ab"), nil, []byte("cde"), []byte("f")}
+	b := NewBuffer("vectored")
+	n, err := b.WriteVectored(srcs...)
+	if err != nil {
+		t.Fatalf("WriteVectored: %v", err)
+	}
+	if n != 6 {
+		t.Fatalf("WriteVectored wrote %d, want 6", n)
+	}
+	want := NewBuffer("concat")
+	want.Write(bytes.Join(srcs, nil))
+	if !bytes.Equal(b.Bytes(), want.Bytes()) || b.Tell() != want.Tell() {
+		t.Fatalf("WriteVectored gave %q at %d, want %q at %d", b.Bytes(), b.Tell(), want.Bytes(), want.Tell())
+	}
+}
+
+func TestWriteVectoredHookOffsets(t *testing.T) {
+	b := NewBuffer("vectored", []byte("xx"))
+	b.Seek(2, io.SeekStart)
+	var got []int64
+	b.SetWriteHook(func(offset int64, data []byte) {
+		got = append(got, offset, int64(len(data)))
+	})
+	if n, err := b.WriteVectored([]byte("ab"), []byte("cde")); n != 5 || err != nil {
+		t.Fatalf("WriteVectored = %d, %v, want 5, nil", n, err)
+	}
+	if want := []int64{2, 2, 4, 3}; !slices.Equal(got, want) {
+		t.Fatalf("hook saw %v, want %v", got, want)
+	}
+}
+
+var vectoredChunks = [][]byte{
+	make([]byte, 16), make([]byte, 64), make([]byte, 8), make([]byte, 256),
+	make([]byte, 32), make([]byte, 4), make([]byte, 128), make([]byte, 16),
+}
+
+func BenchmarkWriteVectored(b *testing.B) {
+	buf := NewBuffer("bench")
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		buf.WriteVectored(vectoredChunks...)
+	}
+}
+
+func BenchmarkWriteSeparate(b *testing.B) {
+	buf := NewBuffer("bench")
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		for _, chunk := range vectoredChunks {
+			buf.Write(chunk)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package crunchio
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// The As*Slice methods reinterpret the bytes of the buffer as a slice of a
+// fixed-size numeric type without copying
+//
+// Elements are read and written in host byte order, not the buffer's byte
+// order, so a view is only portable when the host order matches the data. The
+// slice aliases live memory: later writes to the buffer show through it and
+// writes through it bypass hooks and hashing, and it is only valid until the
+// next operation that grows, shrinks or resets the buffer.
+
+// AsUint16Slice returns the buffer's bytes viewed as a []uint16
+func (b *Buffer) AsUint16Slice() ([]uint16, error) { return asSlice[uint16](b) }
+
+// AsInt16Slice returns the buffer's bytes viewed as a []int16
+func (b *Buffer) AsInt16Slice() ([]int16, error) { return asSlice[int16](b) }
+
+// AsUint32Slice returns the buffer's bytes viewed as a []uint32
+func (b *Buffer) AsUint32Slice() ([]uint32, error) { return asSlice[uint32](b) }
+
+// AsInt32Slice returns the buffer's bytes viewed as a []int32
+func (b *Buffer) AsInt32Slice() ([]int32, error) { return asSlice[int32](b) }
+
+// AsUint64Slice returns the buffer's bytes viewed as a []uint64
+func (b *Buffer) AsUint64Slice() ([]uint64, error) { return asSlice[uint64](b) }
+
+// AsInt64Slice returns the buffer's bytes viewed as a []int64
+func (b *Buffer) AsInt64Slice() ([]int64, error) { return asSlice[int64](b) }
+
+// AsFloat32Slice returns the buffer's bytes viewed as a []float32
+func (b *Buffer) AsFloat32Slice() ([]float32, error) { return asSlice[float32](b) }
+
+// AsFloat64Slice returns the buffer's bytes viewed as a []float64
+func (b *Buffer) AsFloat64Slice() ([]float64, error) { return asSlice[float64](b) }
+
+// asSlice views the bytes of b as a []T, failing if the length is not a
+// multiple of the size of T or the bytes are not suitably aligned
+func asSlice[T Numeric](b *Buffer) ([]T, error) {
+	if b == nil {
+		panic("ASSLICE: buffer is nil")
+	}
+	var zero T
+	size := int64(unsafe.Sizeof(zero))
+	b.Lock()
+	defer b.Unlock()
+	if b.Closed() {
+		return nil, fmt.Errorf("buffer: asslice: %w", ErrClosed)
+	}
+	buffer := b.Buffer()
+	if buffer == nil {
+		return nil, fmt.Errorf("buffer: asslice: crunch buffer vanished")
+	}
+	if b.length%size != 0 {
+		return nil, fmt.Errorf("buffer: asslice: length %d is not a multiple of %T size %d", b.length, zero, size)
+	}
+	if b.length == 0 {
+		return []T{}, nil
+	}
+	_, base, _ := b.span()
+	data := buffer.Bytes()[base : base+b.length]
+	if uintptr(unsafe.Pointer(unsafe.SliceData(data)))%unsafe.Alignof(zero) != 0 {
+		return nil, fmt.Errorf("buffer: asslice: bytes are not aligned for %T", zero)
+	}
+	return unsafe.Slice((*T)(unsafe.Pointer(unsafe.SliceData(data))), b.length/size), nil
+}
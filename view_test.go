@@ -0,0 +1,50 @@
+package crunchio
+
+import (
+	"encoding/binary"
+	"slices"
+	"testing"
+	"unsafe"
+)
+
+// alignedBuffer returns a buffer over n bytes aligned for any numeric view
+func alignedBuffer(n int) *Buffer {
+	words := make([]uint64, (n+7)/8)
+	return NewBuffer("view", unsafe.Slice((*byte)(unsafe.Pointer(unsafe.SliceData(words))), n))
+}
+
+func TestAsUint32Slice(t *testing.T) {
+	b := alignedBuffer(12)
+	view, err := b.AsUint32Slice()
+	if err != nil || len(view) != 3 {
+		t.Fatalf("AsUint32Slice = %v, %v", view, err)
+	}
+	b.WriteAt(binary.NativeEndian.AppendUint32(nil, 0xCAFEBABE), 4)
+	if !slices.Equal(view, []uint32{0, 0xCAFEBABE, 0}) {
+		t.Fatalf("view reads %#x after a write to the buffer", view)
+	}
+	view[2] = 0x01020304
+	if got := b.Bytes()[8:]; !slices.Equal(got, binary.NativeEndian.AppendUint32(nil, 0x01020304)) {
+		t.Fatalf("write through the view left % x", got)
+	}
+	floats, err := b.AsFloat32Slice()
+	if err != nil || len(floats) != 3 || unsafe.Pointer(&floats[0]) != unsafe.Pointer(&view[0]) {
+		t.Fatalf("AsFloat32Slice = %v, %v, want the same memory", floats, err)
+	}
+}
+
+func TestAsSliceLength(t *testing.T) {
+	b := alignedBuffer(6)
+	if _, err := b.AsUint32Slice(); err == nil {
+		t.Fatal("AsUint32Slice over 6 bytes succeeded")
+	}
+	if _, err := b.AsUint64Slice(); err == nil {
+		t.Fatal("AsUint64Slice over 6 bytes succeeded")
+	}
+	if view, err := b.AsInt16Slice(); err != nil || len(view) != 3 {
+		t.Fatalf("AsInt16Slice over 6 bytes = %v, %v", view, err)
+	}
+	if view, err := NewBuffer("view").AsFloat64Slice(); err != nil || len(view) != 0 {
+		t.Fatalf("AsFloat64Slice over no bytes = %v, %v", view, err)
+	}
+}
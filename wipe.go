@@ -0,0 +1,154 @@
+package crunchio
+
+import "fmt"
+
+// ZeroRange sets every byte in [start, end) to zero without moving the offset
+func (b *Buffer) ZeroRange(start, end int64) error {
+	if b == nil {
+		panic("ZERORANGE: buffer is nil")
+	}
+	b.Lock()
+	defer b.Unlock()
+	if b.Closed() {
+		return fmt.Errorf("buffer: zerorange: %w", ErrClosed)
+	}
+	if b.readOnly {
+		return fmt.Errorf("buffer: zerorange: %w", ErrReadOnly)
+	}
+	b.unshare()
+	if b.parent != nil {
+		if b.windowed {
+			if length := b.rootLength(); start < 0 || end < start || end > length {
+				return fmt.Errorf("buffer: zerorange: range [%d, %d) out of bounds for length %d", start, end, length)
+			}
+			start, end = b.windowBase+start, b.windowBase+end
+		}
+		return b.parent.ZeroRange(start, end)
+	}
+	if start < 0 || end < start || end > b.length {
+		return fmt.Errorf("buffer: zerorange: range [%d, %d) out of bounds for length %d", start, end, b.length)
+	}
+	if start == end {
+		return nil
+	}
+	return b.fillLocked("zerorange", start, end-start, 0)
+}
+
+// fillChunk is the size of the chunks Fill writes to a file backing
+const fillChunk = 4096
+
+// Fill sets the n bytes at off to v without moving the offset, growing the
+// buffer if the region runs past its end
+func (b *Buffer) Fill(off, n int64, v byte) error {
+	if b == nil {
+		panic("FILL: buffer is nil")
+	}
+	b.Lock()
+	defer b.Unlock()
+	if b.Closed() {
+		return fmt.Errorf("buffer: fill: %w", ErrClosed)
+	}
+	if b.readOnly {
+		return fmt.Errorf("buffer: fill: %w", ErrReadOnly)
+	}
+	if off < 0 || n < 0 {
+		return fmt.Errorf("buffer: fill: invalid region [%d, %d+%d)", off, off, n)
+	}
+	b.unshare()
+	if b.parent != nil {
+		at, err := b.windowWrite("fill", int(n), off)
+		if err != nil {
+			return err
+		}
+		return b.parent.Fill(at, n, v)
+	}
+	return b.fillLocked("fill", off, n, v)
+}
+
+// Zero sets the n bytes at off to zero, like Fill
+func (b *Buffer) Zero(off, n int64) error {
+	if b == nil {
+		panic("ZERO: buffer is nil")
+	}
+	return b.Fill(off, n, 0)
+}
+
+// fillLocked sets the n bytes at off to v in place for a crunch backing and in
+// fixed-size chunks for a file backing, the caller must hold the lock
+func (b *Buffer) fillLocked(op string, off, n int64, v byte) error {
+	if n == 0 {
+		return nil
+	}
+	if err := b.checkLimit(op, off+n); err != nil {
+		return err
+	}
+	buffer := b.Buffer()
+	if b.file != nil {
+		var chunk [fillChunk]byte
+		if v != 0 {
+			for i := range chunk {
+				chunk[i] = v
+			}
+		}
+		for done := int64(0); done < n; {
+			size := min(n-done, fillChunk)
+			if _, err := b.writeFileAt(chunk[:size], off+done); err != nil {
+				return err
+			}
+			done += size
+		}
+		return nil
+	}
+	if buffer == nil {
+		return fmt.Errorf("buffer: %s: crunch buffer vanished", op)
+	}
+	b.grow(buffer, off, n)
+	b.writes.Add(1)
+	region := buffer.Bytes()[off : off+n]
+	if v == 0 {
+		clear(region)
+		return nil
+	}
+	for i := range region {
+		region[i] = v
+	}
+	return nil
+}
+
+// Wipe zeroes every byte of the buffer along with any spare capacity past its
+// length, intended for clearing secrets before the buffer is discarded, a slice
+// only zeroes the bytes in its window
+func (b *Buffer) Wipe() error {
+	if b == nil {
+		panic("WIPE: buffer is nil")
+	}
+	b.Lock()
+	defer b.Unlock()
+	if b.Closed() {
+		return fmt.Errorf("buffer: wipe: %w", ErrClosed)
+	}
+	if b.readOnly {
+		return fmt.Errorf("buffer: wipe: %w", ErrReadOnly)
+	}
+	b.unshare()
+	if b.parent != nil {
+		if b.windowed {
+			return b.parent.ZeroRange(b.windowBase, b.windowBase+b.rootLength())
+		}
+		return b.parent.Wipe()
+	}
+	if b.file != nil {
+		if _, err := b.writeFileAt(make([]byte, b.length), 0); err != nil {
+			return fmt.Errorf("buffer: wipe: %w", err)
+		}
+		return nil
+	}
+	if b.buffer != nil {
+		// the crunch buffer only exposes its current length, anything past it
+		// such as the bytes left behind by Reset still sits in its capacity
+		data := b.buffer.Bytes()
+		clear(data[:cap(data)])
+		b.writes.Add(1)
+	}
+	return nil
+}
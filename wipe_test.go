@@ -0,0 +1,133 @@
+package crunchio
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestZeroRange(t *testing.T) {
+	b := NewBuffer("wipe", []byte("secret data"))
+	b.Seek(2, io.SeekStart)
+	if err := b.ZeroRange(0, 6); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != "\x00\x00\x00\x00\x00\x00 data" {
+		t.Fatalf("ZeroRange left %q", got)
+	}
+	if b.Tell() != 2 || b.Size() != 11 {
+		t.Fatalf("ZeroRange moved the offset to %d or length to %d", b.Tell(), b.Size())
+	}
+	for _, r := range [][2]int64{{-1, 2}, {4, 3}, {5, 12}} {
+		if err := b.ZeroRange(r[0], r[1]); err == nil {
+			t.Fatalf("ZeroRange(%d, %d) succeeded", r[0], r[1])
+		}
+	}
+	if err := b.ZeroRange(11, 11); err != nil {
+		t.Fatalf("empty ZeroRange at the end = %v", err)
+	}
+}
+
+func TestZeroRangeReference(t *testing.T) {
+	b := NewBuffer("wipe", []byte("abcdef"))
+	if err := b.Reference().ZeroRange(1, 3); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != "a\x00\x00def" {
+		t.Fatalf("ZeroRange through a reference left %q", got)
+	}
+}
+
+func TestWipe(t *testing.T) {
+	b := NewBuffer("wipe", []byte("hunter2"))
+	data := b.Buffer().Bytes()
+	if err := b.Wipe(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, make([]byte, len(data))) {
+		t.Fatalf("Wipe left %q", data)
+	}
+}
+
+func TestWipeAfterReset(t *testing.T) {
+	b := NewBuffer("wipe", []byte("hunter2"))
+	data := b.Buffer().Bytes()
+	b.Reset()
+	if err := b.Wipe(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data[:cap(data)], make([]byte, cap(data))) {
+		t.Fatalf("Wipe after Reset left %q in the capacity", data[:cap(data)])
+	}
+}
+
+func TestFill(t *testing.T) {
+	b := NewBuffer("fill", []byte("abcdef"))
+	b.Seek(3, io.SeekStart)
+	if err := b.Fill(1, 2, '*'); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != "a**def" || b.Tell() != 3 {
+		t.Fatalf("Fill left %q at offset %d", got, b.Tell())
+	}
+	if err := b.Fill(8, 2, '+'); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != "a**def\x00\x00++" {
+		t.Fatalf("Fill past the end left %q", got)
+	}
+	if err := b.Zero(0, 3); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != "\x00\x00\x00def\x00\x00++" || b.Tell() != 3 {
+		t.Fatalf("Zero left %q at offset %d", got, b.Tell())
+	}
+	if err := b.Fill(2, 0, 'x'); err != nil || b.Size() != 10 {
+		t.Fatalf("empty Fill = %v, Size %d", err, b.Size())
+	}
+	for _, r := range [][2]int64{{-1, 2}, {0, -1}} {
+		if err := b.Fill(r[0], r[1], 'x'); err == nil {
+			t.Fatalf("Fill(%d, %d) succeeded", r[0], r[1])
+		}
+		if err := b.Zero(r[0], r[1]); err == nil {
+			t.Fatalf("Zero(%d, %d) succeeded", r[0], r[1])
+		}
+	}
+}
+
+func TestFillSlice(t *testing.T) {
+	b := NewBuffer("fill", []byte("abcdef"))
+	s := b.Slice(2, 3)
+	if err := s.Fill(1, 2, '-'); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != "abc--f" {
+		t.Fatalf("Fill in a slice left %q", got)
+	}
+	if err := s.Zero(2, 2); !errors.Is(err, ErrSliceBounds) {
+		t.Fatalf("Zero past the window = %v, want ErrSliceBounds", err)
+	}
+	if got := b.String(); got != "abc--f" {
+		t.Fatalf("failed Zero in a slice left %q", got)
+	}
+}
+
+func TestFillFile(t *testing.T) {
+	b, f := newTempFileBuffer(t, "head")
+	if err := b.Fill(2, fillChunk+10, 'z'); err != nil {
+		t.Fatal(err)
+	}
+	want := append([]byte("he"), bytes.Repeat([]byte{'z'}, fillChunk+10)...)
+	data, err := os.ReadFile(f.Name())
+	if err != nil || !bytes.Equal(data, want) || b.Size() != int64(len(want)) {
+		t.Fatalf("Fill on a file left %d bytes, %v, Size %d", len(data), err, b.Size())
+	}
+	if err := b.Zero(0, 3); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(f.Name()); !bytes.Equal(data[:4], []byte{0, 0, 0, 'z'}) {
+		t.Fatalf("Zero on a file left % x", data[:4])
+	}
+}
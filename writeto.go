@@ -0,0 +1,107 @@
+package crunchio
+
+import (
+	"fmt"
+	"io"
+)
+
+// writeToChunk is the most WriteTo hands to the destination in one Write
+const writeToChunk = 32 * 1024
+
+// WriteTo writes the unread bytes to w a chunk at a time, advancing the offset
+// past each chunk w accepts, implementing io.WriterTo so io.Copy skips its
+// intermediate buffer
+//
+// Each chunk is copied out under the lock and written after releasing it, so w
+// may use the buffer, though bytes it writes past the offset are picked up by
+// the following chunks.
+func (b *Buffer) WriteTo(w io.Writer) (n int64, err error) {
+	if b == nil {
+		panic("WRITETO: buffer is nil")
+	}
+	if b.tracer != nil {
+		defer b.tracer.StartSpan("WriteTo " + b.name)()
+	}
+	var chunk []byte
+	for {
+		limiter, throttleErr := b.throttle(writeToChunk, b.readDeadline.Load())
+		if throttleErr != nil {
+			return n, fmt.Errorf("buffer: writeto: %w", throttleErr)
+		}
+		at, data, hook, chunkErr := b.nextChunk(&chunk)
+		var wrote int
+		if chunkErr == nil {
+			wrote, chunkErr = w.Write(data)
+			if chunkErr == nil && wrote < len(data) {
+				chunkErr = io.ErrShortWrite
+			}
+			if wrote < len(data) {
+				b.unreadChunk(at, len(data), wrote)
+			}
+		}
+		limiter.refund(writeToChunk - wrote)
+		n += int64(wrote)
+		if hook != nil && wrote > 0 {
+			hook(at, append([]byte{}, data[:wrote]...))
+		}
+		if chunkErr != nil || wrote == 0 {
+			if chunkErr == io.EOF {
+				chunkErr = nil
+			}
+			return n, chunkErr
+		}
+	}
+}
+
+// nextChunk copies the next chunk of unread bytes into chunk under the lock
+// and advances the offset past it, returning io.EOF once none are left along
+// with the read hook to call
+func (b *Buffer) nextChunk(chunk *[]byte) (at int64, data []byte, hook func(int64, []byte), err error) {
+	if err = b.lockUntil(b.readDeadline.Load()); err != nil {
+		return 0, nil, nil, fmt.Errorf("buffer: writeto: %w", err)
+	}
+	defer b.Unlock()
+	if b.Closed() {
+		return 0, nil, nil, fmt.Errorf("buffer: writeto: %w", ErrClosed)
+	}
+	b.clampTruncated()
+	b.alignBit()
+	root, base, end := b.span()
+	if root != b {
+		root.Lock()
+		defer root.Unlock()
+	}
+	at = b.offset
+	length := visibleLength(root.length, base, end)
+	if at >= length {
+		return at, nil, nil, io.EOF
+	}
+	if *chunk == nil {
+		*chunk = make([]byte, writeToChunk)
+	}
+	size := min(length-at, writeToChunk)
+	if root.file != nil {
+		var read int
+		read, err = root.readFileAt((*chunk)[:size], base+at)
+		if err != nil && err != io.EOF {
+			return at, nil, nil, err
+		}
+		data = (*chunk)[:read]
+	} else if root.buffer != nil {
+		data = (*chunk)[:copy((*chunk)[:size], root.buffer.Bytes()[base+at:base+at+size])]
+	} else {
+		return at, nil, nil, io.EOF
+	}
+	b.offset += int64(len(data))
+	return at, data, b.readHook, nil
+}
+
+// unreadChunk moves the offset back over the part of the chunk of n bytes at at
+// that the destination didn't accept, unless it has been moved since
+func (b *Buffer) unreadChunk(at int64, n, wrote int) {
+	b.Lock()
+	defer b.Unlock()
+	if b.offset == at+int64(n) {
+		b.offset = at + int64(wrote)
+	}
+}
@@ -0,0 +1,48 @@
+package crunchio
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+// tellWriter records the offset of its buffer on every write
+type tellWriter struct {
+	b       *Buffer
+	offsets []int64
+	bytes.Buffer
+}
+
+func (w *tellWriter) Write(p []byte) (int, error) {
+	w.offsets = append(w.offsets, w.b.Tell())
+	return w.Buffer.Write(p)
+}
+
+func TestWriteTo(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), writeToChunk/8)
+	b := NewBuffer("writeto", data)
+	b.Seek(3, io.SeekStart)
+	w := &tellWriter{b: b}
+	if n, err := b.WriteTo(w); n != int64(len(data)-3) || err != nil {
+		t.Fatalf("WriteTo = %d, %v", n, err)
+	}
+	if !bytes.Equal(w.Bytes(), data[3:]) {
+		t.Fatal("WriteTo wrote the wrong bytes")
+	}
+	if want := []int64{3 + writeToChunk, int64(len(data))}; len(w.offsets) != 2 || w.offsets[0] != want[0] || w.offsets[1] != want[1] {
+		t.Fatalf("offsets seen by the writer = %v, want %v", w.offsets, want)
+	}
+	if n, err := b.WriteTo(w); n != 0 || err != nil {
+		t.Fatalf("WriteTo at the end = %d, %v", n, err)
+	}
+}
+
+func TestWriteToShort(t *testing.T) {
+	b := NewBuffer("writeto", []byte("0123456789"))
+	if n, err := b.WriteTo(&failingWriter{limit: 4}); n != 4 || err != io.ErrClosedPipe {
+		t.Fatalf("WriteTo = %d, %v", n, err)
+	}
+	if b.Tell() != 4 {
+		t.Fatalf("Tell after a short write = %d, want 4", b.Tell())
+	}
+}